@@ -0,0 +1,172 @@
+package recipe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnaryOp is a unary [Operation] calling a function of its source of
+// type T, see [Unary].
+type UnaryOp[T, R any] struct {
+	fn  func(opts OpOpts, v T) (R, error)
+	doc OperationDoc
+}
+
+// Unary adapts fn into a unary operation on sources of type T.
+//
+// Its source types are checked when recipes are resolved, and it is
+// called without boxing its source by generated and allocation-free
+// walks, see [TypedOperation].
+//
+// e.g., recipe.Unary(func(_ recipe.OpOpts, s string) (bool, error) { return s != "", nil })
+func Unary[T, R any](fn func(opts OpOpts, v T) (R, error)) UnaryOp[T, R] {
+	return UnaryOp[T, R]{fn: fn}
+}
+
+var __ctc__UnaryOp_impl_TypedOperation TypedOperation[string] = UnaryOp[string, bool]{}
+
+func (UnaryOp[T, R]) Arity() OpArity { return OpUnary }
+func (o UnaryOp[T, R]) Execute(opts OpOpts, sources ...any) (any, error) {
+	if len(sources) != 1 {
+		return nil, fmt.Errorf("%w: expects 1 source, got %d", ErrOpMismatch, len(sources))
+	}
+
+	v, err := source[T](sources[0])
+	if err != nil {
+		return nil, err
+	}
+	return o.fn(opts, v)
+}
+func (o UnaryOp[T, R]) ExecuteTyped(opts OpOpts, v T) (any, error) {
+	return o.fn(opts, v)
+}
+
+// WithDoc returns the operation documented by doc, see [DescribedOperation].
+// The input and output types of doc are those of the operation.
+func (o UnaryOp[T, R]) WithDoc(doc OperationDoc) UnaryOp[T, R] {
+	o.doc = doc
+	return o
+}
+
+func (o UnaryOp[T, R]) Doc() OperationDoc {
+	doc := o.doc
+	doc.Input, doc.Output = reflect.TypeFor[T](), reflect.TypeFor[R]()
+	return doc
+}
+
+// BinaryOp is a binary [Operation] calling a function of its two sources
+// of type T, see [Binary].
+type BinaryOp[T, R any] struct {
+	fn  func(opts OpOpts, a, b T) (R, error)
+	doc OperationDoc
+}
+
+// Binary adapts fn into a binary operation on sources of type T, e.g.
+// for diffs. Its source types are checked when recipes are resolved.
+func Binary[T, R any](fn func(opts OpOpts, a, b T) (R, error)) BinaryOp[T, R] {
+	return BinaryOp[T, R]{fn: fn}
+}
+
+var __ctc__BinaryOp_impl_DescribedOperation DescribedOperation = BinaryOp[string, bool]{}
+
+func (BinaryOp[T, R]) Arity() OpArity { return OpBinary }
+func (o BinaryOp[T, R]) Execute(opts OpOpts, sources ...any) (any, error) {
+	if len(sources) != 2 {
+		return nil, fmt.Errorf("%w: expects 2 sources, got %d", ErrOpMismatch, len(sources))
+	}
+
+	a, err := source[T](sources[0])
+	if err != nil {
+		return nil, err
+	}
+	b, err := source[T](sources[1])
+	if err != nil {
+		return nil, err
+	}
+	return o.fn(opts, a, b)
+}
+
+// WithDoc returns the operation documented by doc, see [DescribedOperation].
+// The input and output types of doc are those of the operation.
+func (o BinaryOp[T, R]) WithDoc(doc OperationDoc) BinaryOp[T, R] {
+	o.doc = doc
+	return o
+}
+
+func (o BinaryOp[T, R]) Doc() OperationDoc {
+	doc := o.doc
+	doc.Input, doc.Output = reflect.TypeFor[T](), reflect.TypeFor[R]()
+	return doc
+}
+
+// source asserts a source of type T. Nil sources are the zero T.
+func source[T any](src any) (T, error) {
+	if src == nil {
+		var zero T
+		return zero, nil
+	}
+
+	v, ok := src.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: expects %s source, got %T", ErrOpMismatch, reflect.TypeFor[T](), src)
+	}
+	return v, nil
+}
+
+// checkSourceType checks that the sources op receives from the field of
+// eTree are of its documented input type, if any.
+func checkSourceType(op Operation, eTree *ExecTree) error {
+	dop, ok := op.(DescribedOperation)
+	if !ok {
+		return nil
+	}
+	in := dop.Doc().Input
+	if in == nil {
+		return nil
+	}
+
+	st := sourceType(eTree)
+	if st == in || in.Kind() == reflect.Interface && st.Implements(in) {
+		return nil
+	}
+	return fmt.Errorf("%w: accepts %s sources, field is %s", ErrOpMismatch, in, st)
+}
+
+// sourceType returns the type of the values the field extractor of eTree
+// produces, see [Builder.compileFieldExtractor].
+func sourceType(eTree *ExecTree) reflect.Type {
+	switch eTree.fieldKind {
+	case reflect.Bool:
+		return reflect.TypeFor[bool]()
+	case reflect.Int:
+		return reflect.TypeFor[int]()
+	case reflect.Int8:
+		return reflect.TypeFor[int8]()
+	case reflect.Int16:
+		return reflect.TypeFor[int16]()
+	case reflect.Int32:
+		return reflect.TypeFor[int32]()
+	case reflect.Int64:
+		return reflect.TypeFor[int64]()
+	case reflect.Uint:
+		return reflect.TypeFor[uint]()
+	case reflect.Uint8:
+		return reflect.TypeFor[uint8]()
+	case reflect.Uint16:
+		return reflect.TypeFor[uint16]()
+	case reflect.Uint32:
+		return reflect.TypeFor[uint32]()
+	case reflect.Uint64:
+		return reflect.TypeFor[uint64]()
+	case reflect.Float32:
+		return reflect.TypeFor[float32]()
+	case reflect.Float64:
+		return reflect.TypeFor[float64]()
+	case reflect.String:
+		return reflect.TypeFor[string]()
+	default:
+		// Pointers are typed, others boxed as declared
+		return eTree.fieldType
+	}
+}
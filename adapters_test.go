@@ -0,0 +1,178 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestUnary(t *testing.T) {
+	op := Unary(func(_ OpOpts, s string) (int, error) { return len(s), nil })
+
+	tests := []struct {
+		name    string
+		sources []any
+		want    any
+		err     error
+	}{
+		{"string", []any{"abc"}, 3, nil},
+		{"nil is the zero value", []any{nil}, 0, nil},
+		{"not a string", []any{42}, nil, ErrOpMismatch},
+		{"no source", nil, nil, ErrOpMismatch},
+		{"two sources", []any{"a", "b"}, nil, ErrOpMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := op.Execute(nil, tt.sources...)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	doc := op.WithDoc(OperationDoc{Description: "Length."}).Doc()
+	if doc.Description != "Length." || doc.Input != reflect.TypeFor[string]() || doc.Output != reflect.TypeFor[int]() {
+		t.Errorf("documented as %+v", doc)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	op := Binary(func(_ OpOpts, a, b int) (bool, error) { return a == b, nil })
+
+	tests := []struct {
+		name    string
+		sources []any
+		want    any
+		err     error
+	}{
+		{"equal", []any{1, 1}, true, nil},
+		{"nil is the zero value", []any{0, nil}, true, nil},
+		{"first not an int", []any{"1", 1}, nil, ErrOpMismatch},
+		{"second not an int", []any{1, "1"}, nil, ErrOpMismatch},
+		{"one source", []any{1}, nil, ErrOpMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := op.Execute(nil, tt.sources...)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if op.Arity() != OpBinary {
+		t.Errorf("arity %d, want %d", op.Arity(), OpBinary)
+	}
+	if doc := op.Doc(); doc.Input != reflect.TypeFor[int]() || doc.Output != reflect.TypeFor[bool]() {
+		t.Errorf("documented as %+v", doc)
+	}
+}
+
+type mismatched struct {
+	N int `def:"len=1"`
+}
+
+func TestAdapterSourceType(t *testing.T) {
+	exec := newDefineExecutor(t)
+
+	_, err := exec.ExecuteCombineWalk(nil, []any{&mismatched{}})
+	var diags BuildDiagnostics
+	if !errors.As(err, &diags) || len(diags) != 1 || diags[0].Path != "N" {
+		t.Fatalf("got %v, want a diagnostic of N", err)
+	}
+	if !errors.Is(err, ErrOpMismatch) {
+		t.Errorf("got %v, want %v", err, ErrOpMismatch)
+	}
+}
+
+type pointed struct {
+	N *int `def:"deref"`
+}
+
+func TestAdapterPointerSource(t *testing.T) {
+	exec := newDefineExecutor(t)
+	deref := Unary(func(_ OpOpts, p *int) (int, error) {
+		if p == nil {
+			return -1, nil
+		}
+		return *p, nil
+	})
+	if err := exec.reg.Load().RegisterOperation("deref", deref); err != nil {
+		t.Fatal(err)
+	}
+
+	n := 7
+	for _, tt := range []struct {
+		v    pointed
+		want int
+	}{
+		{pointed{N: &n}, 7},
+		{pointed{}, -1},
+	} {
+		got, err := exec.ExecuteCombineWalk(nil, []any{&tt.v})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]any{"N": tt.want}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+
+	// Pointer fields are typed, not unsafe pointers
+	if err := exec.reg.Load().RegisterOperation("deref", Unary(func(_ OpOpts, p unsafe.Pointer) (bool, error) { return p != nil, nil })); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exec.ExecuteCombineWalk(nil, []any{&pointed{}}); !errors.Is(err, ErrOpMismatch) {
+		t.Errorf("got %v, want %v", err, ErrOpMismatch)
+	}
+}
+
+// typedOnlyOp may only be called unboxed, see [TypedOperation].
+type typedOnlyOp struct{}
+
+func (typedOnlyOp) Arity() OpArity { return OpUnary }
+func (typedOnlyOp) Execute(OpOpts, ...any) (any, error) {
+	return nil, errors.New("called boxed")
+}
+func (typedOnlyOp) ExecuteTyped(_ OpOpts, s string) (any, error) {
+	return s, nil
+}
+
+var __ctc__typedOnlyOp_impl_TypedOperation TypedOperation[string] = typedOnlyOp{}
+
+type typedFields struct {
+	S string `def:"typed"`
+}
+
+type boxedFields struct {
+	N int `def:"typed"`
+}
+
+func TestTypedOperation(t *testing.T) {
+	exec := newDefineExecutor(t)
+	if err := exec.reg.Load().RegisterOperation("typed", typedOnlyOp{}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := exec.ExecuteCombineWalk(nil, []any{&typedFields{S: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"S": "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Fields of other kinds are boxed
+	if _, err := exec.ExecuteCombineWalk(nil, []any{&boxedFields{}}); err == nil {
+		t.Error("int field passed unboxed")
+	}
+}
@@ -0,0 +1,147 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrNotSliceKind = fmt.Errorf("provided batch is not a slice")
+	ErrNilElement   = fmt.Errorf("nil batch element")
+)
+
+// BatchResult holds the outcome of [Executor.ExecuteBatch], indexed like
+// the batch.
+type BatchResult struct {
+	// Results are the combined results of a [CombineWalk], nil for
+	// other walks and failed elements.
+	Results []any
+	// Errors are the errors of failed elements, nil for others.
+	Errors []error
+}
+
+// Err joins the errors of failed elements, prefixed with their index,
+// or returns nil if every element succeeded.
+func (br BatchResult) Err() error {
+	var errs []error
+	for i, err := range br.Errors {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("element %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Failed returns the indices of failed elements.
+func (br BatchResult) Failed() []int {
+	var failed []int
+	for i, err := range br.Errors {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+// ExecuteBatch walks every element of batch, a []T or []*T where T is a
+// struct, with the walk type of the recipe of T.
+//
+// The recipe is resolved once for the whole batch, selected by
+// [ExecContext.Grammar]. vals are the values of every element's
+// [ApplyWalk]. The error is only set when the batch could not be walked
+// at all, failures of single elements are reported in the result.
+//
+// With a parallelism, see [Executor.SetParallelism], elements rather
+// than fields are walked concurrently. A done [ExecContext.Context]
+// fails the elements not walked yet, the operations of elements being
+// walked are not cancelled. Batches are never traced.
+func (exec *Executor) ExecuteBatch(ctx *ExecContext, batch any, vals []any) (BatchResult, error) {
+	bv := reflect.ValueOf(batch)
+	if bv.Kind() != reflect.Slice {
+		return BatchResult{}, fmt.Errorf("%w: %T", ErrNotSliceKind, batch)
+	}
+
+	byPointer := bv.Type().Elem().Kind() == reflect.Pointer
+	wet := bv.Type().Elem()
+	if byPointer {
+		wet = wet.Elem()
+	}
+	if wet.Kind() != reflect.Struct {
+		return BatchResult{}, fmt.Errorf("batch of %s: %w", bv.Type().Elem(), ErrNotStructElem)
+	}
+
+	key := ""
+	if ctx != nil {
+		key = ctx.Grammar
+	}
+
+	rcp, err := exec.resolveRecipeWith(key, wet)
+	if err != nil {
+		return BatchResult{}, fmt.Errorf("resolving recipe: %w", err)
+	}
+
+	rcp, err = exec.applyContext(ctx, rcp)
+	if err != nil {
+		return BatchResult{}, fmt.Errorf("applying exec context: %w", err)
+	}
+
+	n := bv.Len()
+	br := BatchResult{Results: make([]any, n), Errors: make([]error, n)}
+
+	// Elements are walked sequentially and untraced, on the pooled plan
+	// rather than on workers: the batch context is checked between
+	// elements, not between fields.
+	elemCtx := &ExecContext{Grammar: key, Parallelism: 1}
+
+	walked := make([]bool, n)
+	walk := func(goCtx context.Context, i int) error {
+		walked[i] = true
+		br.Results[i], br.Errors[i] = exec.walkElement(goCtx, elemCtx, rcp, bv.Index(i), byPointer, vals)
+		return nil
+	}
+
+	goCtx, workers, concurrent := exec.concurrency(ctx)
+	if !concurrent || workers == 1 {
+		for i := range n {
+			walk(goCtx, i)
+		}
+		return br, nil
+	}
+
+	err = parallelFor(goCtx, n, workers, walk)
+	if err != nil {
+		for i := range n {
+			if !walked[i] {
+				br.Errors[i] = err
+			}
+		}
+	}
+	return br, nil
+}
+
+// walkElement walks ev, an element of a batch, with the resolved recipe rcp.
+func (exec *Executor) walkElement(goCtx context.Context, ctx *ExecContext, rcp *Recipe, ev reflect.Value, byPointer bool, vals []any) (any, error) {
+	if goCtx != nil && goCtx.Err() != nil {
+		return nil, context.Cause(goCtx)
+	}
+
+	if !byPointer {
+		ev = ev.Addr()
+	} else if ev.IsNil() {
+		return nil, ErrNilElement
+	}
+
+	walked := []any{ev.Interface()}
+	switch rcp.WalkType {
+	case CombineWalk:
+		return exec.combineWalk(ctx, rcp, walked)
+	case ApplyWalk:
+		return nil, exec.applyWalk(ctx, rcp, walked, vals)
+	case TransformWalk:
+		return nil, exec.transformWalk(ctx, rcp, walked)
+	default:
+		return nil, fmt.Errorf("unknown walk type %d", rcp.WalkType)
+	}
+}
@@ -0,0 +1,96 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExecuteBatch(t *testing.T) {
+	exec := newDefineExecutor(t)
+
+	batch := []*account{{Name: "a"}, nil, {Name: "c"}}
+	for _, parallelism := range []int{1, 3} {
+		br, err := exec.ExecuteBatch(&ExecContext{Parallelism: parallelism}, batch, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []any{map[string]any{"Name": "a"}, nil, map[string]any{"Name": "c"}}
+		if !reflect.DeepEqual(br.Results, want) {
+			t.Errorf("parallelism %d: got %v, want %v", parallelism, br.Results, want)
+		}
+		if !reflect.DeepEqual(br.Failed(), []int{1}) {
+			t.Errorf("parallelism %d: failed %v, want [1]", parallelism, br.Failed())
+		}
+		if !errors.Is(br.Err(), ErrNilElement) {
+			t.Errorf("parallelism %d: got %v, want %v", parallelism, br.Err(), ErrNilElement)
+		}
+	}
+
+	// Elements walked by value
+	br, err := exec.ExecuteBatch(nil, []account{{Name: "a"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if br.Err() != nil || !reflect.DeepEqual(br.Results, []any{map[string]any{"Name": "a"}}) {
+		t.Errorf("got %v, %v", br.Results, br.Err())
+	}
+}
+
+func TestExecuteBatchErrors(t *testing.T) {
+	exec := newDefineExecutor(t)
+
+	if _, err := exec.ExecuteBatch(nil, account{}, nil); !errors.Is(err, ErrNotSliceKind) {
+		t.Errorf("got %v, want %v", err, ErrNotSliceKind)
+	}
+	if _, err := exec.ExecuteBatch(nil, []int{1}, nil); !errors.Is(err, ErrNotStructElem) {
+		t.Errorf("got %v, want %v", err, ErrNotStructElem)
+	}
+	if _, err := exec.ExecuteBatch(&ExecContext{Grammar: "nope"}, []account{}, nil); !errors.Is(err, ErrGrammarNotFound) {
+		t.Errorf("got %v, want %v", err, ErrGrammarNotFound)
+	}
+}
+
+func TestExecuteBatchCancelled(t *testing.T) {
+	exec := newDefineExecutor(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, parallelism := range []int{1, 2} {
+		br, err := exec.ExecuteBatch(&ExecContext{Context: ctx, Parallelism: parallelism}, []account{{}, {}, {}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, err := range br.Errors {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("parallelism %d: element %d got %v, want %v", parallelism, i, err, context.Canceled)
+			}
+		}
+	}
+}
+
+func TestExecuteBatchSequentialAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not counted reliably under the race detector")
+	}
+
+	exec := newDefineExecutor(t)
+	batch := []account{{Name: "a"}, {Name: "b"}}
+
+	// A context alone keeps elements on the sequential plan
+	run := func(ctx *ExecContext) float64 {
+		return testing.AllocsPerRun(100, func() {
+			if _, err := exec.ExecuteBatch(ctx, batch, nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	plain := run(&ExecContext{})
+	withCtx := run(&ExecContext{Context: context.Background()})
+	if withCtx != plain {
+		t.Errorf("walking with a context allocated %v times, want %v", withCtx, plain)
+	}
+}
@@ -4,6 +4,7 @@ package recipe
 // 1. Differentiation between anon and explicit struct fields
 
 import (
+	"container/list"
 	"errors"
 	"fmt"
 	"reflect"
@@ -17,107 +18,205 @@ var (
 	ErrEmptyTag         = fmt.Errorf("empty tag")
 	ErrExpectedStruct   = fmt.Errorf("expected struct type but got field type")
 	ErrUnexpectedStruct = fmt.Errorf("unexpected struct type for non-struct field")
+	ErrGrammarExists    = fmt.Errorf("grammar already exists")
+	ErrGrammarNotFound  = fmt.Errorf("grammar not found")
 )
 
 type Builder struct {
-	grammar Grammar
-	mu      sync.RWMutex
-	cache   map[reflect.Type]*Recipe
+	grammar  Grammar
+	grammars map[string]Grammar
+	mu       sync.RWMutex
+	cache    map[recipeKey]*cacheEntry
+
+	// LRU order of the built recipes, most recent first.
+	// Bounded by limit, if non-zero.
+	lru      *list.List
+	limit    int
+	inflight map[recipeKey]*buildCall
+
+	// gen is incremented by [Builder.Invalidate] and [Builder.Reset], so
+	// that builds in flight meanwhile do not cache stale recipes.
+	gen uint64
 }
 
+// recipeKey identifies a cached recipe: the key of the grammar it was
+// built with, and its struct type.
+type recipeKey struct {
+	grammar string
+	wt      reflect.Type
+}
+
+// NewBuilder returns a builder for grammar, its default grammar.
+//
+// More grammars can be added with [Builder.AddGrammar].
 func NewBuilder(grammar Grammar) *Builder {
 	return &Builder{
-		grammar: grammar,
-		mu:      sync.RWMutex{},
-		cache:   make(map[reflect.Type]*Recipe),
+		grammar:  grammar,
+		grammars: map[string]Grammar{grammar.Key(): grammar},
+		mu:       sync.RWMutex{},
+		cache:    make(map[recipeKey]*cacheEntry),
+		lru:      list.New(),
+		inflight: make(map[recipeKey]*buildCall),
+	}
+}
+
+// AddGrammar adds grammar to the builder, selected by its key.
+//
+// Recipes are cached per grammar, so the same struct type can be
+// built by several grammars of one builder.
+func (b *Builder) AddGrammar(grammar Grammar) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.grammars[grammar.Key()]; ok {
+		return fmt.Errorf("%w: %s", ErrGrammarExists, grammar.Key())
+	}
+	b.grammars[grammar.Key()] = grammar
+
+	return nil
+}
+
+// Grammar returns the grammar of the given key, or the default grammar
+// if key is empty.
+func (b *Builder) Grammar(key string) (Grammar, error) {
+	if key == "" {
+		return b.grammar, nil
+	}
+
+	b.mu.RLock()
+	grammar, ok := b.grammars[key]
+	b.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrGrammarNotFound, key)
 	}
+	return grammar, nil
 }
 
-// Set manually sets a recipe in the cache
+// Set manually sets a recipe in the cache of the default grammar.
 //
 // t must be the element type (not pointer) of the struct
 // for which the recipe is to be set.
 func (b *Builder) Set(wt reflect.Type, recipe *Recipe) error {
+	return b.SetWith(b.grammar.Key(), wt, recipe)
+}
+
+// SetWith manually sets a recipe in the cache of the grammar of the given key.
+// The recipe is pinned, see [Builder.SetLimit].
+//
+// See: [Builder.Set]
+func (b *Builder) SetWith(key string, wt reflect.Type, recipe *Recipe) error {
 	if wt.Kind() != reflect.Struct {
 		return ErrNotStructKind
 	}
 
 	b.mu.Lock()
-	b.cache[wt] = recipe
+	b.store(recipeKey{key, wt}, recipe, true)
 	b.mu.Unlock()
 
 	return nil
 }
 
 // GetOrBuild retrieves a cached Recipe for the given struct type t,
-// or builds it if not found.
+// or builds it with the default grammar if not found.
 //
 // t must be a struct kind, not a pointer to a struct,
 // for which the recipe is to be retrieved or built.
 func (b *Builder) GetOrBuild(wt reflect.Type) (*Recipe, error) {
+	return b.GetOrBuildWith("", wt)
+}
+
+// GetOrBuildWith is [Builder.GetOrBuild] for the grammar of the given key,
+// or the default grammar if key is empty.
+func (b *Builder) GetOrBuildWith(key string, wt reflect.Type) (*Recipe, error) {
 	if wt.Kind() != reflect.Struct {
 		return nil, ErrNotStructKind
 	}
 
-	b.mu.RLock()
-	recipe, ok := b.cache[wt]
-	b.mu.RUnlock()
+	grammar, err := b.Grammar(key)
+	if err != nil {
+		return nil, err
+	}
 
+	recipe, ok := b.lookup(recipeKey{grammar.Key(), wt})
 	if ok {
 		return recipe, nil
 	}
 
-	return b.Build(wt, true)
+	return b.buildOnce(grammar, wt)
 }
 
-// Build constructs a Recipe for the given struct type t.
+// Build constructs a Recipe for the given struct type t,
+// with the default grammar.
 //
 // t is assumed to be a struct kind, not a pointer to a struct,
 // for which the recipe is to be built.
 //
 // Caches built recipe if requested.
 func (b *Builder) Build(wt reflect.Type, cache bool) (*Recipe, error) {
-	rcp, err := b.buildRecipe(wt)
+	return b.build(b.grammar, wt, cache)
+}
+
+// BuildWith is [Builder.Build] for the grammar of the given key.
+func (b *Builder) BuildWith(key string, wt reflect.Type, cache bool) (*Recipe, error) {
+	grammar, err := b.Grammar(key)
+	if err != nil {
+		return nil, err
+	}
+	return b.build(grammar, wt, cache)
+}
+
+func (b *Builder) build(grammar Grammar, wt reflect.Type, cache bool) (*Recipe, error) {
+	rcp, err := b.buildRecipe(grammar, wt)
 	if err != nil {
 		return nil, err
 	}
 
 	if cache {
 		b.mu.Lock()
-		b.cache[wt] = rcp
+		b.store(recipeKey{grammar.Key(), wt}, rcp, false)
 		b.mu.Unlock()
 	}
 
 	return rcp, nil
 }
 
-func (b *Builder) buildRecipe(wt reflect.Type) (*Recipe, error) {
-	eTree, err := b.buildTree(wt)
-	if err != nil {
+func (b *Builder) buildRecipe(grammar Grammar, wt reflect.Type) (*Recipe, error) {
+	var diags BuildDiagnostics
+	eTree := b.buildTree(grammar, wt, "", &diags)
+	if err := diags.err(); err != nil {
 		return nil, fmt.Errorf("building struct recipe for type %s: %w", wt.Name(), err)
 	}
 
+	return b.newRecipe(grammar, wt, eTree)
+}
+
+// newRecipe wraps eTree, the tree of struct type wt, into a recipe of grammar.
+func (b *Builder) newRecipe(grammar Grammar, wt reflect.Type, eTree *ExecTree) (*Recipe, error) {
 	rcp := &Recipe{
 		Root:     eTree,
-		WalkType: b.grammar.WalkType(),
+		Type:     wt,
+		Grammar:  grammar.Key(),
+		Arity:    grammar.OpArity(),
+		WalkType: grammar.WalkType(),
 		resolved: false,
 	}
 
 	switch rcp.WalkType {
 	case CombineWalk:
-		combiner, err := b.grammar.Combiner()
+		combiner, err := grammar.Combiner()
 		if err != nil {
 			return nil, fmt.Errorf("getting combiner for walk type %d: %w", rcp.WalkType, err)
 		}
 		rcp.combiner = combiner
 	case ApplyWalk:
-		applier, err := b.grammar.Applier()
+		applier, err := grammar.Applier()
 		if err != nil {
 			return nil, fmt.Errorf("getting applier for walk type %d: %w", rcp.WalkType, err)
 		}
 		rcp.applier = applier
 	case TransformWalk:
-		transformer, err := b.grammar.Transformer()
+		transformer, err := grammar.Transformer()
 		if err != nil {
 			return nil, fmt.Errorf("getting transformer for walk type %d: %w", rcp.WalkType, err)
 		}
@@ -127,7 +226,10 @@ func (b *Builder) buildRecipe(wt reflect.Type) (*Recipe, error) {
 	return rcp, nil
 }
 
-func (b *Builder) buildTree(wt reflect.Type) (*ExecTree, error) {
+// buildTree builds the exec tree of struct type wt, at path from the root.
+//
+// Fields with bad tags are left out of the tree, and reported in diags.
+func (b *Builder) buildTree(grammar Grammar, wt reflect.Type, path string, diags *BuildDiagnostics) *ExecTree {
 
 	// Assume struct, iterate fields
 	eTree := &ExecTree{
@@ -138,40 +240,57 @@ func (b *Builder) buildTree(wt reflect.Type) (*ExecTree, error) {
 
 	for i := 0; i < wt.NumField(); i++ {
 		field := wt.Field(i)
+		tag := field.Tag.Get(grammar.Key())
+		fPath := joinPath(path, field.Name)
 
-		if !field.IsExported() {
+		if !field.IsExported() || tag == "-" {
 			continue
 		}
 
 		if field.Type.Kind() == reflect.Struct {
-			cTree, err := b.buildTree(field.Type)
-			if err != nil {
-				return nil, fmt.Errorf("field %s, child exec tree: %w", field.Name, err)
+			cTree := b.buildTree(grammar, field.Type, fPath, diags)
+
+			// Structs without walkable fields (e.g. time.Time) are leaves
+			if cTree.hasChild() {
+				if tag != "" {
+					lazyOps, err := b.buildOps(grammar, field)
+					if err != nil && !errors.Is(err, ErrEmptyTag) {
+						*diags = append(*diags, newDiagnostic(fPath, tag, err))
+					}
+					if lazyOps != nil {
+						cTree.LazyOps = lazyOps
+					}
+				}
+
+				cTree.Name = field.Name
+				cTree.tag = tag
+				cTree.fieldIdx = i
+				cTree.fieldType = field.Type
+				cTree.fieldOffset = field.Offset
+				cTree.fieldKind = field.Type.Kind()
+
+				// Pre-compile struct getter to actual ptr for child struct,
+				// and extractor for operations on the struct as a whole
+				b.compileStructAddressor(cTree)
+				b.compileFieldExtractor(cTree)
+
+				eTree.Children = append(eTree.Children, cTree)
+				continue
 			}
+		}
 
-			cTree.Name = field.Name
-			cTree.fieldIdx = i
-			cTree.fieldType = field.Type
-			cTree.fieldOffset = field.Offset
-			cTree.fieldKind = field.Type.Kind()
-
-			// Pre-compile struct getter to actual ptr for child struct
-			b.compileStructAddressor(cTree)
-
-			eTree.Children = append(eTree.Children, cTree)
+		fTree, err := b.buildField(grammar, field)
+		if errors.Is(err, ErrEmptyTag) {
 			continue
 		}
 
-		fTree, err := b.buildField(field)
 		if err != nil {
-			return nil, fmt.Errorf("field %s, exec tree: %w", field.Name, err)
-		}
-
-		if fTree == nil || errors.Is(err, ErrEmptyTag) {
+			*diags = append(*diags, newDiagnostic(fPath, tag, err))
 			continue
 		}
 
 		// Execution hot-path metadata optimizations
+		fTree.tag = tag
 		fTree.fieldIdx = i
 		fTree.fieldType = field.Type
 		fTree.fieldOffset = field.Offset
@@ -183,41 +302,81 @@ func (b *Builder) buildTree(wt reflect.Type) (*ExecTree, error) {
 		eTree.Children = append(eTree.Children, fTree)
 	}
 
-	return eTree, nil
+	return eTree
+}
+
+func (b *Builder) buildField(grammar Grammar, field reflect.StructField) (*ExecTree, error) {
+	lazyOps, err := b.buildOps(grammar, field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecTree{
+		Name:       field.Name,
+		LazyOps:    lazyOps,
+		Operations: []ResolvedOperation{},
+		Children:   []*ExecTree{},
+	}, nil
 }
 
-func (b *Builder) buildField(field reflect.StructField) (*ExecTree, error) {
-	tag := field.Tag.Get(b.grammar.Key())
+// buildOps parses the grammar tag of a field into its ordered operations.
+//
+// Untagged fields are left to [Grammar.Split].
+// Returns ErrEmptyTag if the field has no operations.
+func (b *Builder) buildOps(grammar Grammar, field reflect.StructField) ([]LazyOperation, error) {
+	tag := field.Tag.Get(grammar.Key())
 
-	if tag == "" || tag == "-" {
+	if tag == "-" {
 		return nil, ErrEmptyTag
 	}
 
-	opStrs, err := b.grammar.Split(tag)
+	return ParseTag(grammar, tag)
+}
+
+// offsetSplitter is implemented by grammars whose operation strings are
+// substrings of the tag, so that [TokenError] offsets from Parse can be
+// located in the tag.
+type offsetSplitter interface {
+	splitOffsets(tag string) ([]string, []int, error)
+}
+
+// ParseTag splits, parses and orders the operations of tag, the value
+// of the grammar key of a field, as the builder does.
+func ParseTag(grammar Grammar, tag string) ([]LazyOperation, error) {
+	var (
+		opStrs []string
+		opOffs []int
+		err    error
+	)
+	if os, ok := grammar.(offsetSplitter); ok {
+		opStrs, opOffs, err = os.splitOffsets(tag)
+	} else {
+		opStrs, err = grammar.Split(tag)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("splitting operations for field %s: %w", field.Name, err)
+		return nil, fmt.Errorf("splitting operations: %w", err)
 	}
 
 	var lazyOps []LazyOperation
-	for _, opStr := range opStrs {
-		lazyOp, err := b.grammar.Parse(opStr)
+	for i, opStr := range opStrs {
+		lazyOp, err := grammar.Parse(opStr)
 		if err != nil {
-			return nil, fmt.Errorf("parsing operation %s for field %s: %w", opStr, field.Name, err)
+			// Token offsets are relative to the operation string
+			off := -1
+			if opOffs != nil {
+				off = opOffs[i]
+			}
+			return nil, fmt.Errorf("parsing operation %s: %w", opStr, shiftTokenError(err, off))
 		}
 		lazyOps = append(lazyOps, lazyOp)
 	}
 
-	orderedOps, err := b.grammar.Order(lazyOps)
+	orderedOps, err := grammar.Order(lazyOps)
 	if err != nil {
-		return nil, fmt.Errorf("ordering operations for field %s: %w", field.Name, err)
+		return nil, fmt.Errorf("ordering operations: %w", err)
 	}
 
-	return &ExecTree{
-		Name:       field.Name,
-		LazyOps:    orderedOps,
-		Operations: []ResolvedOperation{},
-		Children:   []*ExecTree{},
-	}, nil
+	return orderedOps, nil
 }
 
 // Struct node - Raw pointer extractor to parent struct to avoid reflect.NewAt.
@@ -299,8 +458,11 @@ func (b *Builder) compileFieldExtractor(eTree *ExecTree) error {
 			return *(*string)(unsafe.Pointer(uintptr(structPtr) + offset))
 		}
 	case reflect.Ptr:
+		// Typed, so that operations compare and dereference pointees
+		elemType := fieldType.Elem()
 		eTree.fieldExtractor = func(structPtr unsafe.Pointer) any {
-			return *(*unsafe.Pointer)(unsafe.Pointer(uintptr(structPtr) + offset))
+			p := *(*unsafe.Pointer)(unsafe.Pointer(uintptr(structPtr) + offset))
+			return reflect.NewAt(elemType, p).Interface()
 		}
 	case reflect.Slice:
 		// Slice header is 3 words: pointer, len, cap
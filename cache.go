@@ -0,0 +1,268 @@
+package recipe
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// cacheEntry is a cached recipe.
+//
+// elem is its position in the LRU list, nil for pinned recipes, which
+// are never evicted.
+type cacheEntry struct {
+	rcp  *Recipe
+	elem *list.Element
+}
+
+// buildCall is an in-flight build of a recipe, waited on by concurrent
+// callers of [Builder.GetOrBuild] for the same recipe.
+type buildCall struct {
+	wg  sync.WaitGroup
+	rcp *Recipe
+	err error
+}
+
+// SetLimit bounds the number of built recipes cached by the builder,
+// evicting the least recently used ones. Zero means unbounded.
+//
+// Recipes set with [Builder.Set] or defined with [For] are pinned: they
+// never count towards the limit and are never evicted.
+func (b *Builder) SetLimit(limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.limit = max(limit, 0)
+	b.evict()
+}
+
+// Len returns the number of cached recipes, over all grammars.
+func (b *Builder) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.cache)
+}
+
+// Warm builds and caches the recipes of types with the default grammar,
+// so that bad tags fail at startup rather than on first use.
+//
+// types may be struct types or pointers to struct types. Returns the
+// errors of all failed builds, joined.
+func (b *Builder) Warm(types ...reflect.Type) error {
+	return b.WarmWith("", types...)
+}
+
+// WarmWith is [Builder.Warm] for the grammar of the given key.
+func (b *Builder) WarmWith(key string, types ...reflect.Type) error {
+	var errs []error
+	for _, t := range types {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		_, err := b.GetOrBuildWith(key, t)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("warming %s: %w", t, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Warm builds and resolves the recipes of types with the default grammar,
+// so that unknown operations also fail at startup.
+//
+// See: [Builder.Warm]
+func (exec *Executor) Warm(types ...reflect.Type) error {
+	return exec.WarmWith("", types...)
+}
+
+// WarmWith is [Executor.Warm] for the grammar of the given key.
+func (exec *Executor) WarmWith(key string, types ...reflect.Type) error {
+	var errs []error
+	for _, t := range types {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		_, err := exec.resolveRecipeWith(key, t)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("warming %s: %w", t, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Invalidate drops the cached recipes of types, for every grammar,
+// including pinned recipes. They are rebuilt on next use.
+//
+// types may be struct types or pointers to struct types.
+func (b *Builder) Invalidate(types ...reflect.Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, t := range types {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		for key := range b.cache {
+			if key.wt == t {
+				b.remove(key)
+			}
+		}
+		for key := range b.inflight {
+			if key.wt == t {
+				delete(b.inflight, key)
+			}
+		}
+	}
+	b.gen++
+}
+
+// Reset drops every cached recipe, for every grammar, including pinned
+// recipes. Grammars and the cache limit are kept.
+func (b *Builder) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	clear(b.cache)
+	clear(b.inflight)
+	b.lru.Init()
+	b.gen++
+}
+
+// lookup returns the cached recipe of key, marking it as recently used.
+func (b *Builder) lookup(key recipeKey) (*Recipe, bool) {
+	b.mu.RLock()
+	entry, ok := b.cache[key]
+	bounded := b.limit > 0
+	b.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	// The LRU order only needs maintaining when bounded
+	if !bounded || entry.elem == nil {
+		return entry.rcp, true
+	}
+
+	b.mu.Lock()
+	if cur, ok := b.cache[key]; ok && cur == entry {
+		b.lru.MoveToFront(entry.elem)
+	}
+	b.mu.Unlock()
+
+	return entry.rcp, true
+}
+
+// buildOnce builds and caches the recipe of wt with grammar, unless
+// already cached. Concurrent calls for the same recipe share one build.
+//
+// A build overtaken by [Builder.Invalidate] or [Builder.Reset] is
+// returned to its callers but not cached, as it may predate the change
+// the cache was dropped for.
+func (b *Builder) buildOnce(grammar Grammar, wt reflect.Type) (*Recipe, error) {
+	key := recipeKey{grammar.Key(), wt}
+
+	b.mu.Lock()
+	if entry, ok := b.cache[key]; ok {
+		b.mu.Unlock()
+		return entry.rcp, nil
+	}
+	if call, ok := b.inflight[key]; ok {
+		b.mu.Unlock()
+		call.wg.Wait()
+		return call.rcp, call.err
+	}
+
+	call := &buildCall{}
+	call.wg.Add(1)
+	b.inflight[key] = call
+	gen := b.gen
+	b.mu.Unlock()
+
+	call.rcp, call.err = b.buildRecipe(grammar, wt)
+
+	b.mu.Lock()
+	if call.err == nil && b.gen == gen {
+		b.store(key, call.rcp, false)
+	}
+	if b.inflight[key] == call {
+		delete(b.inflight, key)
+	}
+	b.mu.Unlock()
+
+	call.wg.Done()
+	return call.rcp, call.err
+}
+
+// recipes returns the cached recipes.
+func (b *Builder) recipes() map[recipeKey]*Recipe {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	rcps := make(map[recipeKey]*Recipe, len(b.cache))
+	for key, entry := range b.cache {
+		rcps[key] = entry.rcp
+	}
+	return rcps
+}
+
+// replace caches rcp under key in place of old, if still cached, keeping
+// its pinning and position.
+func (b *Builder) replace(key recipeKey, old, rcp *Recipe) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if entry, ok := b.cache[key]; ok && entry.rcp == old {
+		b.cache[key] = &cacheEntry{rcp: rcp, elem: entry.elem}
+	}
+}
+
+// store caches rcp under key, evicting if over the limit.
+//
+// b.mu must be held for writing.
+func (b *Builder) store(key recipeKey, rcp *Recipe, pinned bool) {
+	if entry, ok := b.cache[key]; ok && entry.elem != nil {
+		b.lru.Remove(entry.elem)
+	}
+
+	entry := &cacheEntry{rcp: rcp}
+	if !pinned {
+		entry.elem = b.lru.PushFront(key)
+	}
+	b.cache[key] = entry
+
+	b.evict()
+}
+
+// evict drops least recently used recipes until within the limit.
+//
+// b.mu must be held for writing.
+func (b *Builder) evict() {
+	if b.limit == 0 {
+		return
+	}
+
+	for b.lru.Len() > b.limit {
+		b.remove(b.lru.Back().Value.(recipeKey))
+	}
+}
+
+// remove drops the cached recipe of key.
+//
+// b.mu must be held for writing.
+func (b *Builder) remove(key recipeKey) {
+	entry, ok := b.cache[key]
+	if !ok {
+		return
+	}
+	if entry.elem != nil {
+		b.lru.Remove(entry.elem)
+	}
+	delete(b.cache, key)
+}
@@ -0,0 +1,161 @@
+package recipe
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type cachedA struct{ V int }
+type cachedB struct{ V int }
+type cachedC struct{ V int }
+
+var (
+	cachedAType = reflect.TypeFor[cachedA]()
+	cachedBType = reflect.TypeFor[cachedB]()
+	cachedCType = reflect.TypeFor[cachedC]()
+)
+
+// gatedGrammar counts the tags it splits, and blocks splitting while
+// gate is set, after signalling started.
+type gatedGrammar struct {
+	Grammar
+	splits  atomic.Int64
+	gate    chan struct{}
+	started chan struct{}
+}
+
+func (g *gatedGrammar) Split(tag string) ([]string, error) {
+	if g.splits.Add(1) == 1 && g.gate != nil {
+		close(g.started)
+		<-g.gate
+	}
+	return g.Grammar.Split(tag)
+}
+
+func TestBuilderLimit(t *testing.T) {
+	b := NewBuilder(newDefineGrammar(t))
+	b.SetLimit(2)
+
+	a, err := b.GetOrBuild(cachedAType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.GetOrBuild(cachedBType); err != nil {
+		t.Fatal(err)
+	}
+
+	// A is now more recently used than B, which gets evicted by C
+	if got, _ := b.GetOrBuild(cachedAType); got != a {
+		t.Fatal("A rebuilt while cached")
+	}
+	if _, err := b.GetOrBuild(cachedCType); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Len() != 2 {
+		t.Errorf("cached %d recipes, want 2", b.Len())
+	}
+	rcps := b.recipes()
+	if _, ok := rcps[recipeKey{"def", cachedBType}]; ok {
+		t.Error("least recently used B not evicted")
+	}
+	if rcps[recipeKey{"def", cachedAType}] != a {
+		t.Error("recently used A evicted")
+	}
+
+	// Pinned recipes do not count towards the limit
+	if err := b.Set(cachedBType, &Recipe{Type: cachedBType}); err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != 3 {
+		t.Errorf("cached %d recipes, want 3", b.Len())
+	}
+
+	b.SetLimit(1)
+	if b.Len() != 2 {
+		t.Errorf("cached %d recipes after lowering the limit, want 2", b.Len())
+	}
+	if _, ok := b.recipes()[recipeKey{"def", cachedBType}]; !ok {
+		t.Error("pinned B evicted")
+	}
+}
+
+func TestBuilderConcurrentBuilds(t *testing.T) {
+	g := &gatedGrammar{Grammar: newDefineGrammar(t)}
+	b := NewBuilder(g)
+
+	const n = 16
+	rcps := make([]*Recipe, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rcp, err := b.GetOrBuild(cachedAType)
+			if err != nil {
+				t.Error(err)
+			}
+			rcps[i] = rcp
+		}()
+	}
+	wg.Wait()
+
+	for _, rcp := range rcps[1:] {
+		if rcp != rcps[0] {
+			t.Fatal("concurrent builds returned different recipes")
+		}
+	}
+	if got := g.splits.Load(); got != 1 {
+		t.Errorf("built %d times, want 1", got)
+	}
+}
+
+func TestBuilderInvalidateDuringBuild(t *testing.T) {
+	for name, drop := range map[string]func(b *Builder){
+		"invalidate": func(b *Builder) { b.Invalidate(cachedAType) },
+		"reset":      func(b *Builder) { b.Reset() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := &gatedGrammar{
+				Grammar: newDefineGrammar(t),
+				gate:    make(chan struct{}),
+				started: make(chan struct{}),
+			}
+			b := NewBuilder(g)
+
+			built := make(chan *Recipe)
+			go func() {
+				rcp, err := b.GetOrBuild(cachedAType)
+				if err != nil {
+					t.Error(err)
+				}
+				built <- rcp
+			}()
+
+			<-g.started
+			drop(b)
+			close(g.gate)
+
+			stale := <-built
+			if stale == nil {
+				t.Fatal("build in flight not returned")
+			}
+			if b.Len() != 0 {
+				t.Fatalf("cached %d recipes built before %s, want 0", b.Len(), name)
+			}
+
+			rcp, err := b.GetOrBuild(cachedAType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rcp == stale {
+				t.Error("stale recipe returned after rebuild")
+			}
+			if b.Len() != 1 {
+				t.Errorf("cached %d recipes, want 1", b.Len())
+			}
+		})
+	}
+}
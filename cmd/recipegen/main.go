@@ -0,0 +1,382 @@
+// Command recipegen generates reflection-free combine walks of tagged
+// struct types, preferred by the recipe Executor over its reflective walk.
+//
+// Usage, from the package declaring the types:
+//
+//	//go:generate recipegen -manifest recipe.json -type User,Order
+//
+// Grammars are read from the manifest, see [recipe.Executor.Manifest].
+// Only combine grammars are generated, e.g. validation. Generated code
+// accesses fields directly, and calls operations implementing
+// [recipe.TypedOperation] without boxing their source.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/types"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"recipe"
+)
+
+var (
+	manifestPath = flag.String("manifest", "", "path of the JSON recipe manifest")
+	typeNames    = flag.String("type", "", "comma-separated struct type names; all tagged struct types if empty")
+	grammarKeys  = flag.String("grammar", "", "comma-separated grammar keys; all combine grammars if empty")
+	output       = flag.String("output", "recipe_gen.go", "output file name")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "recipegen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if *manifestPath == "" {
+		return errors.New("no manifest, set -manifest")
+	}
+
+	m, err := recipe.LoadManifest(*manifestPath)
+	if err != nil {
+		return fmt.Errorf("loading manifest: %w", err)
+	}
+
+	grammars, err := combineGrammars(m, split(*grammarKeys))
+	if err != nil {
+		return err
+	}
+
+	// Dependencies are type-checked from source, independently of the
+	// export data format of the toolchain.
+	mode := packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps
+	pkgs, err := packages.Load(&packages.Config{Mode: mode}, ".")
+	if err != nil {
+		return fmt.Errorf("loading package: %w", err)
+	}
+	if len(pkgs) != 1 || len(pkgs[0].Errors) > 0 {
+		packages.PrintErrors(pkgs)
+		return errors.New("loading package failed")
+	}
+	pkg := pkgs[0]
+
+	names := split(*typeNames)
+	if len(names) == 0 {
+		names = structTypes(pkg.Types)
+	}
+
+	g := &generator{pkg: pkg.Types}
+	for _, name := range names {
+		obj, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return fmt.Errorf("type %s not found in %s", name, pkg.PkgPath)
+		}
+
+		for _, grammar := range grammars {
+			if err := g.generate(obj, grammar); err != nil {
+				return fmt.Errorf("type %s, grammar %s: %w", name, grammar.Key(), err)
+			}
+		}
+	}
+
+	src, err := g.source()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*output, src, 0o644)
+}
+
+// combineGrammars builds the combine grammars of m, restricted to keys
+// if not empty.
+func combineGrammars(m recipe.Manifest, keys []string) ([]recipe.Grammar, error) {
+	var grammars []recipe.Grammar
+	for _, gm := range m.Grammars {
+		if len(keys) > 0 && !slices.Contains(keys, gm.Key) {
+			continue
+		}
+		if gm.WalkType != "combine" {
+			if len(keys) > 0 {
+				return nil, fmt.Errorf("grammar %s: walk type %q is not generated, only \"combine\"", gm.Key, gm.WalkType)
+			}
+			continue
+		}
+
+		grammar, err := gm.Grammar()
+		if err != nil {
+			return nil, fmt.Errorf("grammar %s: %w", gm.Key, err)
+		}
+		grammars = append(grammars, grammar)
+	}
+
+	if len(grammars) == 0 {
+		return nil, errors.New("no combine grammar to generate")
+	}
+	return grammars, nil
+}
+
+// structTypes returns the non-generic struct types declared in pkg.
+func structTypes(pkg *types.Package) []string {
+	var names []string
+	for _, name := range pkg.Scope().Names() {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		if _, ok := named.Underlying().(*types.Struct); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// node is a walked field, mirroring the recipe builder.
+type node struct {
+	path string
+	expr string // Field selector from the walked pointer, e.g. w.Address.Zip
+	// arg is the source passed to operations, expr converted as the
+	// executor's field extractors would, e.g. string(w.Status)
+	arg      string
+	ops      []string
+	children []*node
+}
+
+type generator struct {
+	pkg     *types.Package
+	inits   bytes.Buffer
+	walkers bytes.Buffer
+	// labels numbers the labels of nested structs, unique per file.
+	labels int
+}
+
+func (g *generator) generate(obj *types.TypeName, grammar recipe.Grammar) error {
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() > 0 {
+		return errors.New("not a non-generic named type")
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return errors.New("not a struct type")
+	}
+
+	nodes, err := buildNodes(st, grammar, "", "w")
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		// Untagged for this grammar
+		return nil
+	}
+
+	fn := "combine" + obj.Name() + ident(grammar.Key())
+
+	fmt.Fprintf(&g.inits, "\trecipe.RegisterGenerated(recipe.GeneratedRecipe{\n")
+	fmt.Fprintf(&g.inits, "\t\tGrammar: %q,\n", grammar.Key())
+	fmt.Fprintf(&g.inits, "\t\tType: reflect.TypeFor[%s](),\n", obj.Name())
+	fmt.Fprintf(&g.inits, "\t\tFields: []recipe.GeneratedField{\n")
+	for _, leaf := range leaves(nodes) {
+		ops := make([]string, len(leaf.ops))
+		for i, op := range leaf.ops {
+			ops[i] = strconv.Quote(op)
+		}
+		fmt.Fprintf(&g.inits, "\t\t\t{Path: %q, Ops: []string{%s}},\n", leaf.path, strings.Join(ops, ", "))
+	}
+	fmt.Fprintf(&g.inits, "\t\t},\n")
+	fmt.Fprintf(&g.inits, "\t\tCombine: %s,\n", fn)
+	fmt.Fprintf(&g.inits, "\t})\n")
+
+	fmt.Fprintf(&g.walkers, "\nfunc %s(fields []recipe.BoundField, c recipe.Combiner, walked any) (any, error) {\n", fn)
+	fmt.Fprintf(&g.walkers, "\tw := walked.(*%s)\n", obj.Name())
+	fmt.Fprintf(&g.walkers, "\tvar (\n\t\tres any\n\t\terr error\n\t)\n\n")
+	fmt.Fprintf(&g.walkers, "\tacc0 := c.Zero()\n")
+	idx := 0
+	g.emit(nodes, 0, &idx, "")
+	fmt.Fprintf(&g.walkers, "\n\treturn acc0, nil\n}\n")
+
+	return nil
+}
+
+// emit writes the combine of nodes into acc<depth>. idx is the index of
+// the next leaf in the bound fields.
+//
+// Once acc<depth> is done, see [recipe.ShortCircuitCombiner], the
+// remaining nodes are skipped: the root returns, nested structs jump to
+// label, which combines them into their parent.
+func (g *generator) emit(nodes []*node, depth int, idx *int, label string) {
+	indent := strings.Repeat("\t", depth+1)
+	done := "return acc0, nil"
+	if depth > 0 {
+		done = "goto " + label
+	}
+
+	for _, n := range nodes {
+		if n.children == nil {
+			fmt.Fprintf(&g.walkers, "%s// %s\n", indent, n.path)
+			fmt.Fprintf(&g.walkers, "%sres, err = recipe.CombineLeaf(c, &fields[%d], %s)\n", indent, *idx, n.arg)
+			fmt.Fprintf(&g.walkers, "%sif err != nil {\n%s\treturn nil, err\n%s}\n", indent, indent, indent)
+			fmt.Fprintf(&g.walkers, "%sacc%d = c.Combine(acc%d, res)\n", indent, depth, depth)
+			fmt.Fprintf(&g.walkers, "%sif recipe.CombineDone(c, acc%d) {\n%s\t%s\n%s}\n", indent, depth, indent, done, indent)
+			*idx++
+			continue
+		}
+
+		g.labels++
+		cLabel := fmt.Sprintf("done%d", g.labels)
+
+		fmt.Fprintf(&g.walkers, "%s// %s\n%s{\n", indent, n.path, indent)
+		fmt.Fprintf(&g.walkers, "%s\tacc%d := c.Zero()\n", indent, depth+1)
+		g.emit(n.children, depth+1, idx, cLabel)
+		if len(n.children) > 0 {
+			fmt.Fprintf(&g.walkers, "%s%s:\n", indent, cLabel)
+		}
+		fmt.Fprintf(&g.walkers, "%s\tacc%d = c.Combine(acc%d, acc%d)\n", indent, depth, depth, depth+1)
+		fmt.Fprintf(&g.walkers, "%s\tif recipe.CombineDone(c, acc%d) {\n%s\t\t%s\n%s\t}\n", indent, depth, indent, done, indent)
+		fmt.Fprintf(&g.walkers, "%s}\n", indent)
+	}
+}
+
+func (g *generator) source() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by recipegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg.Name())
+	fmt.Fprintf(&buf, "import (\n\t\"reflect\"\n\n\t%q\n)\n\n", reflect.TypeFor[recipe.Recipe]().PkgPath())
+	fmt.Fprintf(&buf, "func init() {\n%s}\n", g.inits.Bytes())
+	buf.Write(g.walkers.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+// buildNodes returns the walked fields of st, as the recipe builder
+// would build them with grammar.
+func buildNodes(st *types.Struct, grammar recipe.Grammar, path, expr string) ([]*node, error) {
+	var nodes []*node
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i)).Get(grammar.Key())
+		if !field.Exported() || tag == "-" {
+			continue
+		}
+
+		n := &node{
+			path: joinPath(path, field.Name()),
+			expr: expr + "." + field.Name(),
+		}
+
+		if fst, ok := field.Type().Underlying().(*types.Struct); ok {
+			children, err := buildNodes(fst, grammar, n.path, n.expr)
+			if err != nil {
+				return nil, err
+			}
+
+			// Structs without walkable fields (e.g. time.Time) are leaves
+			if len(children) > 0 {
+				n.children = children
+				nodes = append(nodes, n)
+				continue
+			}
+		}
+
+		lazyOps, err := recipe.ParseTag(grammar, tag)
+		if errors.Is(err, recipe.ErrEmptyTag) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", n.path, err)
+		}
+
+		for _, lazyOp := range lazyOps {
+			n.ops = append(n.ops, lazyOp.Name)
+		}
+		n.arg = sourceExpr(field.Type(), n.expr)
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
+}
+
+// sourceExpr returns expr, of type typ, converted to the type the
+// executor's field extractors pass to operations: fields of basic kinds
+// are passed as their underlying type, e.g. Status as string.
+func sourceExpr(typ types.Type, expr string) string {
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok || types.Identical(typ, basic) {
+		return expr
+	}
+
+	switch basic.Kind() {
+	case types.Bool, types.String,
+		types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64,
+		types.Float32, types.Float64:
+		return basic.Name() + "(" + expr + ")"
+	default:
+		return expr
+	}
+}
+
+func leaves(nodes []*node) []*node {
+	var ls []*node
+	for _, n := range nodes {
+		if n.children == nil {
+			ls = append(ls, n)
+		} else {
+			ls = append(ls, leaves(n.children)...)
+		}
+	}
+	return ls
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// ident turns a grammar key into an exported identifier suffix.
+func ident(key string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9':
+			if upper {
+				r = []rune(strings.ToUpper(string(r)))[0]
+			}
+			sb.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	return sb.String()
+}
+
+func split(s string) []string {
+	var parts []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"recipe"
+)
+
+const src = `package a
+
+type Address struct {
+	Zip  string ` + "`v:\"required,len=5\"`" + `
+	City string
+}
+
+type User struct {
+	Name    string ` + "`v:\"required\"`" + `
+	Address Address
+	Skip    string ` + "`v:\"-\"`" + `
+	Age     int    ` + "`v:\"min=18\"`" + `
+}
+`
+
+// checkPackage type-checks the files of package a, importing from source.
+func checkPackage(t *testing.T, files ...string) *types.Package {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for i, file := range files {
+		f, err := parser.ParseFile(fset, fmt.Sprintf("a%d.go", i), file, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, f)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("a", fset, parsed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+func newGrammar(t *testing.T, key string) recipe.Grammar {
+	t.Helper()
+
+	grammar, err := recipe.NewGrammarConfig().
+		SetKey(key).
+		SetWalkType(recipe.CombineWalk).
+		SetCombiner(recipe.BoolAndCombiner{}).
+		SetOpArity(recipe.OpUnary).
+		SetArity(recipe.GrammarArityVariadic).
+		SetModifierFormat(recipe.ModFormatMixed).
+		SetFlatStructure().
+		SetFormat(recipe.FlatFormatDelimited, recipe.InlineSepComma).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grammar
+}
+
+func TestGenerate(t *testing.T) {
+	pkg := checkPackage(t, src)
+
+	g := &generator{pkg: pkg}
+	for _, name := range structTypes(pkg) {
+		obj := pkg.Scope().Lookup(name).(*types.TypeName)
+		if err := g.generate(obj, newGrammar(t, "v")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := g.source()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := string(out)
+
+	for _, want := range []string{
+		// Fields are bound in walk order, skipping untagged and `-` fields
+		`{Path: "Name", Ops: []string{"required"}},
+			{Path: "Address.Zip", Ops: []string{"required", "len"}},
+			{Path: "Age", Ops: []string{"min"}},`,
+		"res, err = recipe.CombineLeaf(c, &fields[1], w.Address.Zip)",
+		"res, err = recipe.CombineLeaf(c, &fields[2], w.Age)",
+		// Nested structs jump to their label once done, the root returns
+		"goto done1\n",
+		"done1:\n\t\tacc0 = c.Combine(acc0, acc1)",
+		"if recipe.CombineDone(c, acc0) {\n\t\treturn acc0, nil",
+	} {
+		if !strings.Contains(gen, want) {
+			t.Errorf("generated code lacks %q:\n%s", want, gen)
+		}
+	}
+	for _, unwanted := range []string{"Skip", "City"} {
+		if strings.Contains(gen, unwanted) {
+			t.Errorf("generated code walks %s:\n%s", unwanted, gen)
+		}
+	}
+
+	// The generated code compiles along the walked types
+	checkPackage(t, src, gen)
+}
+
+const mainSrc = `package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"recipe"
+)
+
+type Status string
+
+type Level int
+
+type Address struct {
+	Zip    string ` + "`v:\"nonempty\"`" + `
+	Status Status ` + "`v:\"upper\"`" + `
+}
+
+type User struct {
+	Name    string ` + "`v:\"upper\"`" + `
+	Status  Status ` + "`v:\"nonempty\"`" + `
+	Level   Level  ` + "`v:\"positive\"`" + `
+	Address Address
+}
+
+func main() {
+	grammar, err := recipe.NewGrammarConfig().
+		SetKey("v").
+		SetWalkType(recipe.CombineWalk).
+		SetCombiner(recipe.MapCombiner{}).
+		SetOpArity(recipe.OpUnary).
+		SetArity(recipe.GrammarArityVariadic).
+		SetModifierFormat(recipe.ModFormatMixed).
+		SetFlatStructure().
+		SetFormat(recipe.FlatFormatDelimited, recipe.InlineSepComma).
+		Build()
+	if err != nil {
+		panic(err)
+	}
+
+	reg := recipe.NewOpRegistry()
+	reg.RegisterOperation("nonempty", recipe.Unary(func(_ recipe.OpOpts, s string) (bool, error) { return s != "", nil }))
+	reg.RegisterOperation("upper", recipe.Unary(func(_ recipe.OpOpts, s string) (string, error) { return strings.ToUpper(s), nil }))
+	reg.RegisterOperation("positive", recipe.Unary(func(_ recipe.OpOpts, n int) (bool, error) { return n > 0, nil }))
+	exec := recipe.NewExecutor(reg, recipe.NewBuilder(grammar))
+
+	u := User{Name: "ada", Status: "active", Level: 2, Address: Address{Zip: "12345", Status: "moved"}}
+	for _, ctx := range []*recipe.ExecContext{nil, {Explain: recipe.ExplainRecord}} {
+		res, err := exec.ExecuteCombineWalk(ctx, []any{&u})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(res)
+	}
+}
+`
+
+// runGenerated runs the main package of files with the generated code,
+// in a module using this tree of recipe, and returns its output.
+func runGenerated(t *testing.T, files ...string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds a module")
+	}
+
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	mod := fmt.Sprintf("module a\n\ngo 1.24.5\n\nrequire recipe v0.0.0\n\nreplace recipe => %s\n", root)
+	write := map[string][]byte{"go.mod": []byte(mod), "go.sum": sum}
+	for i, file := range files {
+		write[fmt.Sprintf("a%d.go", i)] = []byte(file)
+	}
+	for name, data := range write {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("running generated code: %v\n%s", err, out)
+	}
+	return string(out)
+}
+
+func TestGeneratedWalk(t *testing.T) {
+	pkg := checkPackage(t, mainSrc)
+
+	g := &generator{pkg: pkg}
+	for _, name := range structTypes(pkg) {
+		obj := pkg.Scope().Lookup(name).(*types.TypeName)
+		if err := g.generate(obj, newGrammar(t, "v")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := g.source()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Named fields are passed as the reflective walk passes them
+	if want := "recipe.CombineLeaf(c, &fields[1], string(w.Status))"; !strings.Contains(string(out), want) {
+		t.Errorf("generated code lacks %q:\n%s", want, out)
+	}
+
+	// The generated walk combines as the reflective one
+	lines := strings.Split(strings.TrimSpace(runGenerated(t, mainSrc, string(out))), "\n")
+	want := "map[Address.Status:MOVED Address.Zip:true Level:true Name:ADA Status:true]"
+	if len(lines) != 2 || lines[0] != want || lines[1] != want {
+		t.Errorf("generated and reflective walks combined:\n%s\nwant %s", strings.Join(lines, "\n"), want)
+	}
+}
+
+func TestGenerateUntagged(t *testing.T) {
+	pkg := checkPackage(t, src)
+
+	g := &generator{pkg: pkg}
+	if err := g.generate(pkg.Scope().Lookup("User").(*types.TypeName), newGrammar(t, "other")); err != nil {
+		t.Fatal(err)
+	}
+	if g.inits.Len() != 0 || g.walkers.Len() != 0 {
+		t.Errorf("generated a walk of a type untagged for the grammar:\n%s%s", g.inits.Bytes(), g.walkers.Bytes())
+	}
+}
+
+func TestCombineGrammars(t *testing.T) {
+	m := recipe.Manifest{Grammars: []recipe.GrammarManifest{
+		{Key: "v", WalkType: "combine", Format: "delimited", Separator: ",", Arity: "variadic", ModifierFormat: "mixed"},
+		{Key: "env", WalkType: "apply", Format: "delimited", Separator: ",", Arity: "variadic", ModifierFormat: "mixed"},
+	}}
+
+	grammars, err := combineGrammars(m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grammars) != 1 || grammars[0].Key() != "v" {
+		t.Errorf("got %d grammars, want only v", len(grammars))
+	}
+
+	if _, err := combineGrammars(m, []string{"env"}); err == nil {
+		t.Error("generated a non-combine grammar")
+	}
+	if _, err := combineGrammars(m, []string{"nope"}); err == nil {
+		t.Error("generated without grammars")
+	}
+}
+
+func TestIdent(t *testing.T) {
+	tests := map[string]string{
+		"v":        "V",
+		"validate": "Validate",
+		"my-key":   "MyKey",
+		"my_key2":  "MyKey2",
+	}
+	for key, want := range tests {
+		if got := ident(key); got != want {
+			t.Errorf("ident(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Command recipelint reports malformed recipe struct tags.
+//
+// Usage:
+//
+//	recipelint -manifest recipe.json ./...
+//
+// or, through go vet:
+//
+//	go vet -vettool=$(which recipelint) -recipelint.manifest=recipe.json ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"recipe/lint"
+)
+
+func main() {
+	singlechecker.Main(lint.Analyzer)
+}
@@ -0,0 +1,312 @@
+package recipe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// Combiners whose results differ in type from their accumulator are
+// [FieldCombiner]s: CombineField folds the results of a field's operations,
+// Combine merges the accumulators of fields and nested structs.
+
+var (
+	__ctc__BoolOrCombiner_impl_Combiner                      Combiner             = BoolOrCombiner{}
+	__ctc__ShortCircuitAndCombiner_impl_ShortCircuitCombiner ShortCircuitCombiner = ShortCircuitAndCombiner{}
+	__ctc__ShortCircuitOrCombiner_impl_ShortCircuitCombiner  ShortCircuitCombiner = ShortCircuitOrCombiner{}
+	__ctc__ErrorJoinCombiner_impl_FieldCombiner              FieldCombiner        = ErrorJoinCombiner{}
+	__ctc__CountCombiner_impl_FieldCombiner                  FieldCombiner        = CountCombiner{}
+	__ctc__SumCombiner_impl_FieldCombiner                    FieldCombiner        = SumCombiner{}
+	__ctc__MinCombiner_impl_FieldCombiner                    FieldCombiner        = MinCombiner{}
+	__ctc__MaxCombiner_impl_FieldCombiner                    FieldCombiner        = MaxCombiner{}
+	__ctc__MapCombiner_impl_FieldCombiner                    FieldCombiner        = MapCombiner{}
+	__ctc__JSONObjectCombiner_impl_FieldCombiner             FieldCombiner        = JSONObjectCombiner{}
+	__ctc__StringJoinCombiner_impl_Combiner                  Combiner             = StringJoinCombiner{}
+	__ctc__TemplateJoinCombiner_impl_FieldCombiner           FieldCombiner        = TemplateJoinCombiner{}
+)
+
+// BoolOrCombiner combines bool results, true if any is.
+type BoolOrCombiner struct{}
+
+func (c BoolOrCombiner) Zero() any { return false }
+func (c BoolOrCombiner) Combine(acc, result any) any {
+	return acc.(bool) || result.(bool)
+}
+
+// ShortCircuitAndCombiner is a [BoolAndCombiner] whose result is decided
+// by the first false, a [ShortCircuitCombiner]: the walk stops there.
+type ShortCircuitAndCombiner struct{}
+
+func (c ShortCircuitAndCombiner) Zero() any { return true }
+func (c ShortCircuitAndCombiner) Combine(acc, result any) any {
+	return acc.(bool) && result.(bool)
+}
+
+// Done reports whether acc is decided, i.e. false.
+func (c ShortCircuitAndCombiner) Done(acc any) bool { return !acc.(bool) }
+
+// ShortCircuitOrCombiner is a [BoolOrCombiner] whose result is decided
+// by the first true, a [ShortCircuitCombiner]: the walk stops there.
+type ShortCircuitOrCombiner struct{}
+
+func (c ShortCircuitOrCombiner) Zero() any { return false }
+func (c ShortCircuitOrCombiner) Combine(acc, result any) any {
+	return acc.(bool) || result.(bool)
+}
+
+// Done reports whether acc is decided, i.e. true.
+func (c ShortCircuitOrCombiner) Done(acc any) bool { return acc.(bool) }
+
+// ErrorJoinCombiner collects the non-nil error results of operations into
+// a single error, like [errors.Join], nil if there are none. Each error is
+// prefixed with the path of its field, e.g. "Address.Zip: too short".
+//
+// Results that are not errors are ignored.
+type ErrorJoinCombiner struct{}
+
+func (c ErrorJoinCombiner) Zero() any { return nil }
+func (c ErrorJoinCombiner) Combine(acc, result any) any {
+	return joinErrors(acc, result)
+}
+func (c ErrorJoinCombiner) CombineField(acc any, path string, result any) any {
+	err, ok := result.(error)
+	if !ok || err == nil {
+		return acc
+	}
+	return joinErrors(acc, fmt.Errorf("%s: %w", path, err))
+}
+
+// joinErrors joins the errors of a and b, flattening joined errors.
+func joinErrors(a, b any) any {
+	var errs []error
+	for _, v := range []any{a, b} {
+		switch err := v.(type) {
+		case interface{ Unwrap() []error }:
+			errs = append(errs, err.Unwrap()...)
+		case error:
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// CountCombiner counts the results of operations that are true, or not
+// nil and not bool, e.g. validation failures or [FieldChange]s, into an int.
+type CountCombiner struct{}
+
+func (c CountCombiner) Zero() any { return 0 }
+func (c CountCombiner) Combine(acc, result any) any {
+	return acc.(int) + result.(int)
+}
+func (c CountCombiner) CombineField(acc any, path string, result any) any {
+	if b, ok := result.(bool); ok && !b || result == nil {
+		return acc
+	}
+	return acc.(int) + 1
+}
+
+// SumCombiner sums the numeric results of operations into a float64.
+// Results that are not numbers are ignored.
+type SumCombiner struct{}
+
+func (c SumCombiner) Zero() any { return float64(0) }
+func (c SumCombiner) Combine(acc, result any) any {
+	return acc.(float64) + result.(float64)
+}
+func (c SumCombiner) CombineField(acc any, path string, result any) any {
+	n, ok := toFloat(result)
+	if !ok {
+		return acc
+	}
+	return acc.(float64) + n
+}
+
+// MinCombiner combines the numeric results of operations into the least,
+// as a float64, nil if there are none. Results that are not numbers are
+// ignored.
+type MinCombiner struct{}
+
+func (c MinCombiner) Zero() any { return nil }
+func (c MinCombiner) Combine(acc, result any) any {
+	return foldFloat(acc, result, math.Min)
+}
+func (c MinCombiner) CombineField(acc any, path string, result any) any {
+	n, ok := toFloat(result)
+	if !ok {
+		return acc
+	}
+	return foldFloat(acc, n, math.Min)
+}
+
+// MaxCombiner combines the numeric results of operations into the
+// greatest, as a float64, nil if there are none. Results that are not
+// numbers are ignored.
+type MaxCombiner struct{}
+
+func (c MaxCombiner) Zero() any { return nil }
+func (c MaxCombiner) Combine(acc, result any) any {
+	return foldFloat(acc, result, math.Max)
+}
+func (c MaxCombiner) CombineField(acc any, path string, result any) any {
+	n, ok := toFloat(result)
+	if !ok {
+		return acc
+	}
+	return foldFloat(acc, n, math.Max)
+}
+
+// foldFloat folds the float64 accumulators a and b, either nil if empty.
+func foldFloat(a, b any, fold func(x, y float64) float64) any {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	default:
+		return fold(a.(float64), b.(float64))
+	}
+}
+
+// toFloat converts a numeric value, of a named type or not, to float64.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case nil:
+		return 0, false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// MapCombiner collects the results of operations into a map[string]any by
+// field path, e.g. "Address.Zip". Fields with several operations map to
+// the result of the last.
+type MapCombiner struct{}
+
+func (c MapCombiner) Zero() any { return map[string]any(nil) }
+func (c MapCombiner) Combine(acc, result any) any {
+	m, child := acc.(map[string]any), result.(map[string]any)
+	if len(child) == 0 {
+		return m
+	}
+	if m == nil {
+		return child
+	}
+	maps.Copy(m, child)
+	return m
+}
+func (c MapCombiner) CombineField(acc any, path string, result any) any {
+	m := acc.(map[string]any)
+	if m == nil {
+		m = map[string]any{}
+	}
+	m[path] = result
+	return m
+}
+
+// JSONObjectCombiner builds a JSON object of the results of operations,
+// keyed by field path, e.g. {"Name":"J***","Address.Zip":"***"}, as a
+// [json.RawMessage]. Fields with several operations have a member per
+// result. Error results are written as their message, results that do
+// not marshal as null.
+type JSONObjectCombiner struct{}
+
+func (c JSONObjectCombiner) Zero() any { return json.RawMessage("{}") }
+func (c JSONObjectCombiner) Combine(acc, result any) any {
+	obj, child := acc.(json.RawMessage), result.(json.RawMessage)
+	if len(child) <= 2 {
+		return obj
+	}
+	if len(obj) <= 2 {
+		return child
+	}
+	obj = append(obj[:len(obj)-1], ',')
+	return append(obj, child[1:]...)
+}
+func (c JSONObjectCombiner) CombineField(acc any, path string, result any) any {
+	obj := acc.(json.RawMessage)
+
+	// Errors marshal as empty objects, write their message
+	if err, ok := result.(error); ok {
+		result = err.Error()
+	}
+
+	val, err := json.Marshal(result)
+	if err != nil {
+		val = []byte("null")
+	}
+	key, _ := json.Marshal(path)
+
+	if len(obj) > 2 {
+		obj = append(obj[:len(obj)-1], ',')
+	} else {
+		obj = append(obj[:0:0], '{')
+	}
+	obj = append(obj, key...)
+	obj = append(obj, ':')
+	obj = append(obj, val...)
+	return append(obj, '}')
+}
+
+// FieldResult is the result of an operation on the field at Path, as
+// rendered by a [TemplateJoinCombiner].
+type FieldResult struct {
+	Path   string
+	Result any
+}
+
+// TemplateJoinCombiner renders the results of operations with a
+// [text/template] of a [FieldResult], joining non-empty renderings with
+// Sep. See [NewTemplateJoinCombiner].
+type TemplateJoinCombiner struct {
+	Template *template.Template
+	Sep      string
+}
+
+// NewTemplateJoinCombiner parses text into the template of a
+// [TemplateJoinCombiner] joining with sep.
+//
+// e.g., recipe.NewTemplateJoinCombiner("{{.Path}}={{.Result}}", "&")
+func NewTemplateJoinCombiner(text, sep string) (TemplateJoinCombiner, error) {
+	tmpl, err := template.New("field").Parse(text)
+	if err != nil {
+		return TemplateJoinCombiner{}, fmt.Errorf("parsing field template: %w", err)
+	}
+	return TemplateJoinCombiner{Template: tmpl, Sep: sep}, nil
+}
+
+func (c TemplateJoinCombiner) Zero() any { return "" }
+func (c TemplateJoinCombiner) Combine(acc, result any) any {
+	return joinNonEmpty(acc.(string), result.(string), c.Sep)
+}
+
+// CombineField renders result, or writes the error of a failed rendering.
+func (c TemplateJoinCombiner) CombineField(acc any, path string, result any) any {
+	var sb strings.Builder
+	if err := c.Template.Execute(&sb, FieldResult{Path: path, Result: result}); err != nil {
+		sb.Reset()
+		fmt.Fprintf(&sb, "%s: %v", path, err)
+	}
+	return joinNonEmpty(acc.(string), sb.String(), c.Sep)
+}
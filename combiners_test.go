@@ -0,0 +1,190 @@
+package recipe
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type scoredInner struct {
+	Score float64 `def:"echo"`
+	Err   error   `def:"echo"`
+}
+
+type scored struct {
+	Name  string `def:"echo"`
+	Score int    `def:"echo"`
+	Err   error  `def:"echo"`
+	Inner scoredInner
+}
+
+func TestCombiners(t *testing.T) {
+	exec := newDefineExecutor(t)
+	errA := errors.New("a")
+	v := scored{Name: "Ada", Score: 2, Err: errA, Inner: scoredInner{Score: 0.5}}
+
+	tmpl, err := NewTemplateJoinCombiner("{{.Path}}={{.Result}}", "&")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		combiner Combiner
+		want     any
+	}{
+		{"map", MapCombiner{}, map[string]any{"Name": "Ada", "Score": 2, "Err": errA, "Inner.Score": 0.5, "Inner.Err": nil}},
+		{"count", CountCombiner{}, 4},
+		{"sum", SumCombiner{}, 2.5},
+		{"min", MinCombiner{}, 0.5},
+		{"max", MaxCombiner{}, float64(2)},
+		{"json", JSONObjectCombiner{}, json.RawMessage(`{"Name":"Ada","Score":2,"Err":"a","Inner.Score":0.5,"Inner.Err":null}`)},
+		{"template", tmpl, "Name=Ada&Score=2&Err=a&Inner.Score=0.5&Inner.Err=<no value>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Combined alike by the plan, traced and parallel walks
+			for _, ctx := range []*ExecContext{
+				{CombinerOverride: tt.combiner},
+				{CombinerOverride: tt.combiner, Explain: ExplainRecord},
+				{CombinerOverride: tt.combiner, Parallelism: 3},
+			} {
+				got, err := exec.ExecuteCombineWalk(ctx, []any{&v})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("explain %d, parallelism %d: got %v, want %v", ctx.Explain, ctx.Parallelism, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorJoinCombiner(t *testing.T) {
+	exec := newDefineExecutor(t)
+	ctx := &ExecContext{CombinerOverride: ErrorJoinCombiner{}}
+	errA, errB := errors.New("a"), errors.New("b")
+
+	got, err := exec.ExecuteCombineWalk(ctx, []any{&scored{Err: errA, Inner: scoredInner{Err: errB}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined, ok := got.(error)
+	if !ok || joined.Error() != "Err: a\nInner.Err: b" {
+		t.Fatalf("got %v", got)
+	}
+	if !errors.Is(joined, errA) || !errors.Is(joined, errB) {
+		t.Error("joined errors do not wrap their results")
+	}
+
+	// Joined errors are flattened
+	if errs := joined.(interface{ Unwrap() []error }).Unwrap(); len(errs) != 2 {
+		t.Errorf("joined %d errors, want 2", len(errs))
+	}
+
+	got, err = exec.ExecuteCombineWalk(ctx, []any{&scored{Name: "Ada"}})
+	if err != nil || got != nil {
+		t.Errorf("no errors: got %v, %v", got, err)
+	}
+}
+
+func TestBoolCombiners(t *testing.T) {
+	tests := []struct {
+		name     string
+		combiner Combiner
+		results  []bool
+		want     bool
+		done     bool
+	}{
+		{"or none", BoolOrCombiner{}, nil, false, false},
+		{"or", BoolOrCombiner{}, []bool{false, true, false}, true, false},
+		{"short-circuit and", ShortCircuitAndCombiner{}, []bool{true, false}, false, true},
+		{"short-circuit and undecided", ShortCircuitAndCombiner{}, []bool{true, true}, true, false},
+		{"short-circuit or", ShortCircuitOrCombiner{}, []bool{false, true}, true, true},
+		{"short-circuit or undecided", ShortCircuitOrCombiner{}, []bool{false}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := tt.combiner.Zero()
+			for _, res := range tt.results {
+				acc = tt.combiner.Combine(acc, res)
+			}
+			if acc != tt.want {
+				t.Errorf("got %v, want %v", acc, tt.want)
+			}
+			if done := CombineDone(tt.combiner, acc); done != tt.done {
+				t.Errorf("done %t, want %t", done, tt.done)
+			}
+		})
+	}
+}
+
+type celsius float32
+
+func TestNumericCombiners(t *testing.T) {
+	// Named and sized numbers are numbers, others are ignored
+	acc := SumCombiner{}.Zero()
+	for _, res := range []any{celsius(1.5), uint8(2), int64(-1), "3", nil, true} {
+		acc = SumCombiner{}.CombineField(acc, "", res)
+	}
+	if acc != 2.5 {
+		t.Errorf("summed %v, want 2.5", acc)
+	}
+
+	// Empty accumulators have no extremum
+	if got := (MinCombiner{}).Combine(nil, nil); got != nil {
+		t.Errorf("min of none: got %v", got)
+	}
+	if got := (MaxCombiner{}).Combine(nil, 1.0); got != 1.0 {
+		t.Errorf("max of one: got %v", got)
+	}
+	if got := (MinCombiner{}).CombineField(nil, "", "1"); got != nil {
+		t.Errorf("min of a string: got %v", got)
+	}
+}
+
+func TestJSONObjectCombiner(t *testing.T) {
+	c := JSONObjectCombiner{}
+
+	acc := c.Zero()
+	acc = c.CombineField(acc, "A", func() {})
+	acc = c.Combine(acc, c.Zero())
+	acc = c.Combine(acc, c.CombineField(c.Zero(), "B", "b"))
+	if got, want := string(acc.(json.RawMessage)), `{"A":null,"B":"b"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// The zero value is not appended to
+	zero := c.Zero()
+	c.CombineField(zero, "A", 1)
+	if string(zero.(json.RawMessage)) != "{}" {
+		t.Errorf("zero value modified to %s", zero)
+	}
+}
+
+func TestStringCombiners(t *testing.T) {
+	c := StringJoinCombiner{Sep: ", "}
+	acc := c.Zero()
+	for _, res := range []string{"", "a", "", "b"} {
+		acc = c.Combine(acc, res)
+	}
+	if acc != "a, b" {
+		t.Errorf("joined %q, want %q", acc, "a, b")
+	}
+
+	if _, err := NewTemplateJoinCombiner("{{.Path", ","); err == nil {
+		t.Error("parsed an unterminated template")
+	}
+
+	tmpl, err := NewTemplateJoinCombiner("{{.Result.Missing}}", ",")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tmpl.CombineField("", "A", 1); got == "" || got.(string)[:3] != "A: " {
+		t.Errorf("failed rendering written as %q", got)
+	}
+}
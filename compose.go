@@ -0,0 +1,364 @@
+package recipe
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrMacroInvalid = fmt.Errorf("invalid macro")
+	ErrMacroCycle   = fmt.Errorf("macro cycle")
+)
+
+// Macro is a named tag fragment, see [OpRegistry.RegisterMacro].
+type Macro struct {
+	// Name is the registered name, e.g. "username"
+	Name string
+	// Params are the parameters of the macro, in declaration order.
+	Params []MacroParam
+	// Body is the tag fragment the macro expands to,
+	// e.g. "required,min=3,max=${max}"
+	Body string
+}
+
+// MacroParam is a parameter of a [Macro].
+type MacroParam struct {
+	Name string
+	// Default is the value of the parameter when not passed, as written
+	// in the signature.
+	Default string
+	// Required parameters have no default, and must be passed.
+	Required bool
+}
+
+// RegisterMacro registers a macro, a tag fragment standing for the
+// operations it contains, e.g. "password" for
+// "required,min=8,max=64,has_upper,has_digit".
+//
+// Macros are expanded when recipes are resolved, not when they are built:
+// [ParseTag] and the [Builder] keep a tag naming the macro as a single
+// operation of the macro name, with its arguments as modifiers. Resolving
+// parses the body in its place, by the grammar of the tag, and reports
+// bad arguments. Changing the body changes every tag using it.
+//
+// The signature may declare parameters, with or without default, that
+// the body references as `${name}`:
+//
+//	reg.RegisterMacro("username(min,max=32)", "required,minlen=${min},maxlen=${max}")
+//
+// Tags pass them in parentheses, e.g. `validate:"username(min=3)"`.
+// Parameters without default are required. Values are substituted as
+// written, quotes included. Macros may use other macros.
+//
+// Conflicts with registered names follow the registry's policy.
+func (reg *OpRegistry) RegisterMacro(signature, body string) error {
+	m, err := parseMacro(signature, body)
+	if err != nil {
+		return err
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.frozen {
+		return fmt.Errorf("%w: registering macro %s", ErrRegistryFrozen, m.Name)
+	}
+	if err := reg.conflict(m.Name); err != nil {
+		return err
+	}
+
+	delete(reg.operations, m.Name)
+	delete(reg.aliases, m.Name)
+	reg.macros[m.Name] = m
+	reg.changed()
+	return nil
+}
+
+// RegisterPipeline registers under name a unary operation piping its
+// source through the operations registered under steps, in order: the
+// result of each step is the source of the next.
+//
+// Steps are resolved when recipes using the pipeline are resolved,
+// preferring the namespace of name, and receive the modifiers of the
+// tag. Conflicts with registered names follow the registry's policy.
+//
+// e.g., reg.RegisterPipeline("slug", "trim", "lower", "dasherize")
+func (reg *OpRegistry) RegisterPipeline(name string, steps ...string) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("%w: pipeline %s has no steps", ErrOpInvalid, name)
+	}
+
+	p := pipeline{reg: reg, name: name, steps: slices.Clone(steps)}
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		p.namespace = name[:i]
+	}
+	return reg.Register(name, p)
+}
+
+// RegisterMacro registers the namespaced macro, see [OpRegistry.RegisterMacro].
+func (ns OpNamespace) RegisterMacro(signature, body string) error {
+	return ns.reg.RegisterMacro(namespaced(ns.namespace, signature), body)
+}
+
+// RegisterPipeline registers the namespaced pipeline, see
+// [OpRegistry.RegisterPipeline]. Its steps resolve in the namespace first.
+func (ns OpNamespace) RegisterPipeline(name string, steps ...string) error {
+	return ns.reg.RegisterPipeline(namespaced(ns.namespace, name), steps...)
+}
+
+// parseMacro parses a macro signature, `<name>` or `<name>(<params>)`.
+func parseMacro(signature, body string) (Macro, error) {
+	name, params, isCall := cutCall(strings.TrimSpace(signature))
+	if !isCall {
+		name = strings.TrimSpace(signature)
+	}
+	if !isOpKey(name) {
+		return Macro{}, fmt.Errorf("%w: name %q", ErrMacroInvalid, name)
+	}
+	if strings.TrimSpace(body) == "" {
+		return Macro{}, fmt.Errorf("%w: macro %s has no body", ErrMacroInvalid, name)
+	}
+
+	m := Macro{Name: name, Body: body}
+
+	toks, err := splitTokens(params, ',')
+	if err != nil {
+		return Macro{}, fmt.Errorf("%w: macro %s: %w", ErrMacroInvalid, name, err)
+	}
+	for _, tok := range toks {
+		key, def, hasDefault := strings.Cut(tok, "=")
+		param := MacroParam{Name: strings.TrimSpace(key), Default: strings.TrimSpace(def), Required: !hasDefault}
+		if !isOpKey(param.Name) || slices.ContainsFunc(m.Params, func(p MacroParam) bool { return p.Name == param.Name }) {
+			return Macro{}, fmt.Errorf("%w: macro %s parameter %q", ErrMacroInvalid, name, param.Name)
+		}
+		m.Params = append(m.Params, param)
+	}
+
+	// References to undeclared parameters are typos
+	for rest := body; ; {
+		_, after, ok := strings.Cut(rest, "${")
+		if !ok {
+			break
+		}
+		ref, after, ok := strings.Cut(after, "}")
+		if !ok {
+			break
+		}
+		if !slices.ContainsFunc(m.Params, func(p MacroParam) bool { return p.Name == ref }) {
+			return Macro{}, fmt.Errorf("%w: macro %s references undeclared parameter %s", ErrMacroInvalid, name, ref)
+		}
+		rest = after
+	}
+
+	return m, nil
+}
+
+// bind returns the body of m with the parameters of a call substituted.
+func (m Macro) bind(opts OpOpts) (string, error) {
+	args, _ := opts.(Modifiers)
+	for key := range args {
+		if !slices.ContainsFunc(m.Params, func(p MacroParam) bool { return p.Name == key }) {
+			return "", fmt.Errorf("%w: macro %s has no parameter %s", ErrMacroInvalid, m.Name, key)
+		}
+	}
+
+	if len(m.Params) == 0 {
+		return m.Body, nil
+	}
+
+	oldnew := make([]string, 0, 2*len(m.Params))
+	for _, p := range m.Params {
+		val, ok := args[p.Name]
+		switch {
+		case ok:
+		case p.Required:
+			return "", fmt.Errorf("%w: macro %s parameter %s is required", ErrMacroInvalid, m.Name, p.Name)
+		default:
+			val = p.Default
+		}
+		oldnew = append(oldnew, "${"+p.Name+"}", val)
+	}
+	return strings.NewReplacer(oldnew...).Replace(m.Body), nil
+}
+
+// expandMacros returns lazyOps with their macros expanded, recursively,
+// and ordered by grammar.
+func (reg *OpRegistry) expandMacros(grammar Grammar, lazyOps []LazyOperation) ([]LazyOperation, error) {
+	var expanded []LazyOperation
+	changed := false
+	for _, lazyOp := range lazyOps {
+		ops, err := reg.expand(grammar, lazyOp, nil)
+		if err != nil {
+			return nil, err
+		}
+		changed = changed || len(ops) != 1 || ops[0].Name != lazyOp.Name
+		expanded = append(expanded, ops...)
+	}
+	if !changed {
+		return lazyOps, nil
+	}
+	return grammar.Order(expanded)
+}
+
+// expand expands lazyOp if it names a macro, seen being the macros
+// being expanded.
+func (reg *OpRegistry) expand(grammar Grammar, lazyOp LazyOperation, seen []string) ([]LazyOperation, error) {
+	m, ok := reg.getMacro(grammar.Key(), lazyOp.Name)
+	if !ok {
+		return []LazyOperation{lazyOp}, nil
+	}
+
+	if slices.Contains(seen, m.Name) {
+		return nil, fmt.Errorf("%w: %s", ErrMacroCycle, strings.Join(append(seen, m.Name), " -> "))
+	}
+	seen = append(seen, m.Name)
+
+	body, err := m.bind(lazyOp.Opts)
+	if err != nil {
+		return nil, err
+	}
+
+	lazyOps, err := ParseTag(grammar, body)
+	if err != nil {
+		return nil, fmt.Errorf("macro %s: %w", m.Name, err)
+	}
+
+	var expanded []LazyOperation
+	for _, op := range lazyOps {
+		ops, err := reg.expand(grammar, op, seen)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, ops...)
+	}
+	return expanded, nil
+}
+
+// getMacro returns the macro named name, preferring the one of the same
+// name in namespace, and following aliases.
+func (reg *OpRegistry) getMacro(namespace, name string) (Macro, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	if len(reg.macros) == 0 {
+		return Macro{}, false
+	}
+	if m, ok := reg.macros[reg.target(namespaced(namespace, name))]; ok {
+		return m, true
+	}
+	m, ok := reg.macros[reg.target(name)]
+	return m, ok
+}
+
+// pipeline is the [OperationFactory] registered by [OpRegistry.RegisterPipeline].
+type pipeline struct {
+	reg       *OpRegistry
+	name      string
+	namespace string
+	steps     []string
+}
+
+var (
+	__ctc__pipeline_impl_OperationFactory   OperationFactory   = pipeline{}
+	__ctc__pipeline_impl_DescribedOperation DescribedOperation = pipeline{}
+	__ctc__pipe_impl_DescribedOperation     DescribedOperation = pipe{}
+)
+
+func (pipeline) Arity() OpArity { return OpUnary }
+func (p pipeline) Doc() OperationDoc {
+	steps := make([]string, len(p.steps))
+	for i, step := range p.steps {
+		steps[i] = "`" + step + "`"
+	}
+	return OperationDoc{Description: "Pipes its source through " + strings.Join(steps, ", then ") + "."}
+}
+func (p pipeline) New(opts OpOpts) (Operation, error) {
+	return p.build(opts, nil)
+}
+func (p pipeline) Execute(opts OpOpts, sources ...any) (any, error) {
+	op, err := p.New(opts)
+	if err != nil {
+		return nil, err
+	}
+	return op.Execute(opts, sources...)
+}
+
+// build resolves the steps of p, seen being the pipelines being built.
+func (p pipeline) build(opts OpOpts, seen []string) (Operation, error) {
+	if slices.Contains(seen, p.name) {
+		return nil, fmt.Errorf("%w: pipeline cycle %s", ErrOpInvalid, strings.Join(append(seen, p.name), " -> "))
+	}
+	seen = append(seen, p.name)
+
+	pp := pipe{steps: p.steps}
+	for _, step := range p.steps {
+		rOp, err := p.reg.resolveOperation(p.namespace, LazyOperation{Name: step, Opts: opts})
+		if err != nil {
+			return nil, fmt.Errorf("pipeline step %s: %w", step, err)
+		}
+
+		rOp.Opts, err = describedOpts(rOp.Op, rOp.Opts)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline step %s: %w", step, err)
+		}
+
+		var op Operation
+		if sub, ok := rOp.Op.(pipeline); ok {
+			op, err = sub.build(rOp.Opts, seen)
+		} else {
+			op, err = instantiate(rOp)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pipeline step %s: %w", step, err)
+		}
+
+		if op.Arity() != OpUnary {
+			return nil, fmt.Errorf("%w: pipeline step %s arity %d is not unary", ErrOpInvalid, step, op.Arity())
+		}
+
+		pp.ops = append(pp.ops, op)
+		pp.opts = append(pp.opts, rOp.Opts)
+	}
+	return pp, nil
+}
+
+// pipe is a [pipeline] with its steps resolved.
+type pipe struct {
+	steps []string
+	ops   []Operation
+	opts  []OpOpts
+}
+
+func (pipe) Arity() OpArity { return OpUnary }
+
+// Doc documents the input of the first step, and output of the last,
+// so that sources are checked against the first step.
+func (p pipe) Doc() OperationDoc {
+	var doc OperationDoc
+	if len(p.ops) == 0 {
+		return doc
+	}
+	if dop, ok := p.ops[0].(DescribedOperation); ok {
+		doc.Input = dop.Doc().Input
+	}
+	if dop, ok := p.ops[len(p.ops)-1].(DescribedOperation); ok {
+		doc.Output = dop.Doc().Output
+	}
+	return doc
+}
+func (p pipe) Execute(_ OpOpts, sources ...any) (any, error) {
+	if len(sources) != 1 {
+		return nil, fmt.Errorf("%w: pipeline expects 1 source, got %d", ErrOpMismatch, len(sources))
+	}
+
+	v := sources[0]
+	for i, op := range p.ops {
+		var err error
+		v, err = op.Execute(p.opts[i], v)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline step %s: %w", p.steps[i], err)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,254 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type macroed struct {
+	Zip   string `def:"zip"`
+	Short string `def:"zip(n=3)"`
+	Exact string `def:"exact(n=2)"`
+}
+
+func newMacroExecutor(t *testing.T) *Executor {
+	t.Helper()
+
+	exec := newDefineExecutor(t)
+	reg := exec.reg.Load()
+	if err := reg.RegisterMacro("zip(n=5)", "len=${n}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterMacro("exact(n)", "zip(n=${n})"); err != nil {
+		t.Fatal(err)
+	}
+	return exec
+}
+
+func TestMacro(t *testing.T) {
+	exec := newMacroExecutor(t)
+
+	got, err := exec.ExecuteCombineWalk(nil, []any{&macroed{Zip: "12345", Short: "123", Exact: "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"Zip": true, "Short": true, "Exact": false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Changing the body changes the tags using it
+	if err := exec.reg.Load().RegisterMacro("zip(n=5)", "echo"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = exec.ExecuteCombineWalk(nil, []any{&macroed{Zip: "12345", Short: "123", Exact: "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.(map[string]any)["Zip"] != "12345" {
+		t.Errorf("zip not re-expanded: got %v", got)
+	}
+}
+
+type (
+	macroMissing struct {
+		A string `def:"exact"`
+	}
+	macroUnknown struct {
+		A string `def:"zip(m=1)"`
+	}
+	macroCycle struct {
+		A string `def:"ping"`
+	}
+)
+
+func TestMacroErrors(t *testing.T) {
+	exec := newMacroExecutor(t)
+	reg := exec.reg.Load()
+	if err := reg.RegisterMacro("ping", "pong"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterMacro("pong", "ping"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		v    any
+		err  error
+	}{
+		{"missing required", &macroMissing{}, ErrMacroInvalid},
+		{"unknown parameter", &macroUnknown{}, ErrMacroInvalid},
+		{"cycle", &macroCycle{}, ErrMacroCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := exec.ExecuteCombineWalk(nil, []any{tt.v})
+			var diags BuildDiagnostics
+			if !errors.As(err, &diags) || len(diags) != 1 || diags[0].Path != "A" {
+				t.Fatalf("got %v, want a diagnostic of A", err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+type macroLater struct {
+	A string `def:"later(n=3)"`
+}
+
+func TestMacroExpandedAtResolution(t *testing.T) {
+	exec := newMacroExecutor(t)
+
+	// Building keeps the call, unknown parameters included
+	got, err := ParseTag(newDefineGrammar(t), "zip(m=1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []LazyOperation{{Name: "zip", Opts: Modifiers{"m": "1"}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parsed %#v, want %#v", got, want)
+	}
+	if _, err := exec.builder.GetOrBuild(reflect.TypeFor[macroUnknown]()); err != nil {
+		t.Fatalf("building: %v", err)
+	}
+	if _, err := exec.ExecuteCombineWalk(nil, []any{&macroUnknown{}}); !errors.Is(err, ErrMacroInvalid) {
+		t.Errorf("resolving: got %v, want %v", err, ErrMacroInvalid)
+	}
+
+	// Macros registered after the build are expanded on resolution
+	if _, err := exec.builder.GetOrBuild(reflect.TypeFor[macroLater]()); err != nil {
+		t.Fatalf("building: %v", err)
+	}
+	if err := exec.reg.Load().RegisterMacro("later(n)", "len=${n}"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := exec.ExecuteCombineWalk(nil, []any{&macroLater{A: "abc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"A": true}; !reflect.DeepEqual(res, want) {
+		t.Errorf("got %v, want %v", res, want)
+	}
+}
+
+func TestParseMacro(t *testing.T) {
+	m, err := parseMacro("username(min, max=32)", "minlen=${min},maxlen=${max}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Macro{
+		Name:   "username",
+		Params: []MacroParam{{Name: "min", Required: true}, {Name: "max", Default: "32"}},
+		Body:   "minlen=${min},maxlen=${max}",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("parsed %+v, want %+v", m, want)
+	}
+
+	body, err := m.bind(Modifiers{"min": "3"})
+	if err != nil || body != "minlen=3,maxlen=32" {
+		t.Errorf("bound %q, %v", body, err)
+	}
+
+	for _, tt := range []struct{ signature, body string }{
+		{"", "echo"},
+		{"bad name", "echo"},
+		{"empty", " "},
+		{"dup(a,a)", "echo"},
+		{"typo(min)", "len=${mni}"},
+		{"unclosed(a", "echo"},
+	} {
+		if _, err := parseMacro(tt.signature, tt.body); !errors.Is(err, ErrMacroInvalid) {
+			t.Errorf("%q: got %v, want %v", tt.signature, err, ErrMacroInvalid)
+		}
+	}
+}
+
+type (
+	piped struct {
+		Name string `def:"shout"`
+	}
+	mispiped struct {
+		N int `def:"shout"`
+	}
+)
+
+func newPipelineExecutor(t *testing.T) *Executor {
+	t.Helper()
+
+	exec := newDefineExecutor(t)
+	reg := exec.reg.Load()
+	if err := reg.RegisterOperation("trim", Unary(func(_ OpOpts, s string) (string, error) { return strings.TrimSpace(s), nil })); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterOperation("upper", Unary(func(_ OpOpts, s string) (string, error) { return strings.ToUpper(s), nil })); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterPipeline("shout", "trim", "upper"); err != nil {
+		t.Fatal(err)
+	}
+	return exec
+}
+
+func TestPipeline(t *testing.T) {
+	exec := newPipelineExecutor(t)
+	reg := exec.reg.Load()
+
+	got, err := exec.ExecuteCombineWalk(nil, []any{&piped{Name: " ada "}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"Name": "ADA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Sources are checked against the first step
+	if _, err := exec.ExecuteCombineWalk(nil, []any{&mispiped{}}); !errors.Is(err, ErrOpMismatch) {
+		t.Errorf("got %v, want %v", err, ErrOpMismatch)
+	}
+
+	// Steps receive the modifiers of the tag
+	if err := reg.RegisterPipeline("zipped", "trim", "len"); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := reg.Lookup("zipped")
+	ok, err := info.Op.Execute(Modifiers{"len": "5"}, " 12345 ")
+	if err != nil || ok != true {
+		t.Errorf("got %v, %v", ok, err)
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	exec := newPipelineExecutor(t)
+	reg := exec.reg.Load()
+
+	if err := reg.RegisterPipeline("empty"); !errors.Is(err, ErrOpInvalid) {
+		t.Errorf("no steps: got %v, want %v", err, ErrOpInvalid)
+	}
+
+	if err := reg.RegisterPipeline("lost", "trim", "missing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterPipeline("loop", "trim", "loop"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"lost", ErrOpNotFound},
+		{"loop", ErrOpInvalid},
+	}
+	for _, tt := range tests {
+		info, _ := reg.Lookup(tt.name)
+		if _, err := info.Op.Execute(nil, "a"); !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
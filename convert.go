@@ -0,0 +1,118 @@
+package recipe
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrParseValue = fmt.Errorf("cannot parse value")
+)
+
+var (
+	stringSliceType     = reflect.TypeOf([]string(nil))
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// DefaultSliceSeparator separates the elements of a string parsed into
+// a slice field.
+const DefaultSliceSeparator = ","
+
+func isTextUnmarshaler(field reflect.Value) bool {
+	return field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType)
+}
+
+// parseInto parses s into field according to the field's type.
+//
+// Supports [encoding.TextUnmarshaler], [time.Duration], elementary kinds,
+// pointers to those, and slices of those separated by
+// [DefaultSliceSeparator].
+func parseInto(field reflect.Value, s string) error {
+	if isTextUnmarshaler(field) {
+		err := field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		if err != nil {
+			return parseError(field, s, err)
+		}
+		return nil
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return parseError(field, s, err)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	// Fields are only set once parsed, malformed values leave them as is
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return parseError(field, s, err)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return parseError(field, s, err)
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return parseError(field, s, err)
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return parseError(field, s, err)
+		}
+		field.SetFloat(f)
+	case reflect.Pointer:
+		elem := reflect.New(field.Type().Elem())
+		if err := parseInto(elem.Elem(), s); err != nil {
+			return err
+		}
+		field.Set(elem)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			field.SetBytes([]byte(s))
+			return nil
+		}
+		if s == "" {
+			field.Set(reflect.MakeSlice(field.Type(), 0, 0))
+			return nil
+		}
+		return parseSliceInto(field, strings.Split(s, DefaultSliceSeparator))
+	default:
+		return fmt.Errorf("%w: unsupported kind %s", ErrParseValue, field.Kind())
+	}
+
+	return nil
+}
+
+func parseError(field reflect.Value, s string, err error) error {
+	return fmt.Errorf("%w: %q into %s: %w", ErrParseValue, s, field.Type(), err)
+}
+
+// parseSliceInto parses each element of parts into a new slice stored in field.
+func parseSliceInto(field reflect.Value, parts []string) error {
+	slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		if err := parseInto(slice.Index(i), strings.TrimSpace(part)); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	field.Set(slice)
+	return nil
+}
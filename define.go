@@ -0,0 +1,222 @@
+package recipe
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrFieldPath    = fmt.Errorf("invalid field path")
+	ErrNoOperations = fmt.Errorf("no operations")
+)
+
+// RecipeDef defines the recipe of struct type T in code, without struct tags.
+//
+// Intended for types that cannot be tagged, e.g. from third-party packages:
+//
+//	rcp, err := recipe.For[User]().
+//		Field("Email", recipe.Op("required"), recipe.Op("email")).
+//		Tag("Address.Zip", "required,len=5").
+//		Build(builder)
+//
+// Only the defined fields are walked, struct tags of T are ignored.
+// Errors are reported by [RecipeDef.Build].
+type RecipeDef[T any] struct {
+	grammar string
+	fields  []fieldDef
+}
+
+type fieldDef struct {
+	path     string
+	ops      []LazyOperation
+	tag      string
+	strategy *MultiOpStrategy
+}
+
+// For starts the definition of the recipe of struct type T.
+func For[T any]() *RecipeDef[T] {
+	return &RecipeDef[T]{}
+}
+
+// Op returns the operation name, with modifiers given as "key=value",
+// or "key" for boolean modifiers.
+//
+// Modifiers are not validated against the grammar, see [RecipeDef.Tag].
+func Op(name string, mods ...string) LazyOperation {
+	if len(mods) == 0 {
+		return LazyOperation{Name: name}
+	}
+
+	opts := make(Modifiers, len(mods))
+	for _, mod := range mods {
+		key, val, _ := strings.Cut(mod, "=")
+		opts[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return LazyOperation{Name: name, Opts: opts}
+}
+
+// Field appends ops to the field at path, a dotted path of exported
+// field names from T, e.g. "Address.Zip".
+//
+// Operations on a struct field are only executed by [ApplyWalk].
+func (def *RecipeDef[T]) Field(path string, ops ...LazyOperation) *RecipeDef[T] {
+	def.fields = append(def.fields, fieldDef{path: path, ops: ops})
+	return def
+}
+
+// Tag appends the operations of tag, written as the grammar's struct tag
+// value, to the field at path.
+//
+// See: [RecipeDef.Field]
+func (def *RecipeDef[T]) Tag(path string, tag string) *RecipeDef[T] {
+	def.fields = append(def.fields, fieldDef{path: path, tag: tag})
+	return def
+}
+
+// Strategy sets the multi-op strategy of the field at path.
+func (def *RecipeDef[T]) Strategy(path string, strategy MultiOpStrategy) *RecipeDef[T] {
+	def.fields = append(def.fields, fieldDef{path: path, strategy: &strategy})
+	return def
+}
+
+// Grammar selects the grammar of the given key, see [Builder.AddGrammar].
+// Defaults to the builder's default grammar.
+func (def *RecipeDef[T]) Grammar(key string) *RecipeDef[T] {
+	def.grammar = key
+	return def
+}
+
+// Build compiles the definition with the selected grammar of b, and
+// caches the recipe in b, replacing any recipe of T for that grammar.
+func (def *RecipeDef[T]) Build(b *Builder) (*Recipe, error) {
+	wt := reflect.TypeFor[T]()
+	if wt.Kind() != reflect.Struct {
+		return nil, ErrNotStructKind
+	}
+
+	grammar, err := b.Grammar(def.grammar)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &ExecTree{
+		Name:     wt.Name(),
+		LazyOps:  []LazyOperation{},
+		Children: []*ExecTree{},
+	}
+
+	for _, fd := range def.fields {
+		node, err := b.defineNode(root, wt, fd.path)
+		if err != nil {
+			return nil, fmt.Errorf("defining recipe for type %s: %w", wt.Name(), err)
+		}
+
+		lazyOps := fd.ops
+		if fd.tag != "" {
+			lazyOps, err = ParseTag(grammar, fd.tag)
+			if err != nil {
+				return nil, fmt.Errorf("defining recipe for type %s: %w", wt.Name(), newDiagnostic(fd.path, fd.tag, err))
+			}
+		}
+		node.LazyOps = append(node.LazyOps, lazyOps...)
+		if node.tag == "" {
+			node.tag = fd.tag
+		}
+
+		if fd.strategy != nil {
+			node.OpStrategy = *fd.strategy
+		}
+	}
+
+	if err := checkDefinedOps(root, ""); err != nil {
+		return nil, fmt.Errorf("defining recipe for type %s: %w", wt.Name(), err)
+	}
+
+	rcp, err := b.newRecipe(grammar, wt, root)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.SetWith(grammar.Key(), wt, rcp); err != nil {
+		return nil, err
+	}
+	return rcp, nil
+}
+
+// defineNode returns the node of the field at path from root, of struct
+// type wt, creating it and its parents as needed.
+func (b *Builder) defineNode(root *ExecTree, wt reflect.Type, path string) (*ExecTree, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: empty path", ErrFieldPath)
+	}
+
+	node, st := root, wt
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		if st.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%w: %s: %s is not a struct", ErrFieldPath, path, strings.Join(names[:i], "."))
+		}
+
+		field, ok := st.FieldByName(name)
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: %s: no field %s in %s", ErrFieldPath, path, name, st.Name())
+		case len(field.Index) != 1:
+			return nil, fmt.Errorf("%w: %s: promoted field %s must be addressed through its embedded struct", ErrFieldPath, path, name)
+		case !field.IsExported():
+			return nil, fmt.Errorf("%w: %s: field %s is unexported", ErrFieldPath, path, name)
+		}
+
+		node = b.defineChild(node, field)
+		st = field.Type
+	}
+
+	return node, nil
+}
+
+// defineChild returns the child of node for field, creating it in field order.
+func (b *Builder) defineChild(node *ExecTree, field reflect.StructField) *ExecTree {
+	idx := field.Index[0]
+	pos, found := slices.BinarySearchFunc(node.Children, idx, func(cTree *ExecTree, idx int) int {
+		return cTree.fieldIdx - idx
+	})
+	if found {
+		return node.Children[pos]
+	}
+
+	cTree := &ExecTree{
+		Name:        field.Name,
+		fieldIdx:    idx,
+		fieldType:   field.Type,
+		fieldOffset: field.Offset,
+		fieldKind:   field.Type.Kind(),
+		LazyOps:     []LazyOperation{},
+		Operations:  []ResolvedOperation{},
+		Children:    []*ExecTree{},
+	}
+
+	if cTree.fieldKind == reflect.Struct {
+		b.compileStructAddressor(cTree)
+	}
+	b.compileFieldExtractor(cTree)
+
+	node.Children = slices.Insert(node.Children, pos, cTree)
+	return cTree
+}
+
+// checkDefinedOps reports leaves without operations, e.g. a path only
+// given a strategy.
+func checkDefinedOps(eTree *ExecTree, path string) error {
+	for _, cTree := range eTree.Children {
+		cPath := joinPath(path, cTree.Name)
+		if !cTree.hasChild() && len(cTree.LazyOps) == 0 {
+			return fmt.Errorf("field %s: %w", cPath, ErrNoOperations)
+		}
+		if err := checkDefinedOps(cTree, cPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type definedPair struct {
+	A, B int
+}
+
+type definedUser struct {
+	Name    string
+	Email   string
+	Pair    definedPair
+	Address struct {
+		Zip  string
+		City string
+	}
+}
+
+// newDefineGrammar returns a `def` tag grammar combining into a map of
+// field paths to results.
+func newDefineGrammar(t *testing.T) Grammar {
+	t.Helper()
+
+	grammar, err := NewGrammarConfig().
+		SetKey("def").
+		SetWalkType(CombineWalk).
+		SetCombiner(MapCombiner{}).
+		SetOpArity(OpUnary).
+		SetArity(GrammarArityVariadic).
+		SetModifierFormat(ModFormatMixed).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grammar
+}
+
+func newDefineExecutor(t *testing.T) *Executor {
+	t.Helper()
+
+	reg := NewOpRegistry()
+	reg.RegisterOperation("echo", Unary(func(_ OpOpts, v any) (any, error) { return v, nil }))
+	reg.RegisterOperation("len", Unary(func(opts OpOpts, s string) (bool, error) {
+		n, err := modInt(opts, "len", 0)
+		return len(s) == n, err
+	}))
+	return NewExecutor(reg, NewBuilder(newDefineGrammar(t)))
+}
+
+func TestRecipeDef(t *testing.T) {
+	exec := newDefineExecutor(t)
+
+	_, err := For[definedUser]().
+		Field("Name", Op("echo")).
+		Tag("Address.Zip", "len=5").
+		Field("Pair", Op("echo")).
+		Build(exec.builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := definedUser{Name: "Ada", Email: "ignored", Pair: definedPair{A: 1, B: 2}}
+	u.Address.Zip = "12345"
+	want := map[string]any{"Name": "Ada", "Pair": definedPair{A: 1, B: 2}, "Address.Zip": true}
+
+	// Every walk of the recipe reads the same fields
+	ctxs := map[string]*ExecContext{
+		"plan":     nil,
+		"traced":   {Explain: ExplainRecord},
+		"parallel": {Parallelism: 2},
+	}
+	for name, ctx := range ctxs {
+		got, err := exec.ExecuteCombineWalk(ctx, []any{&u})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s combined %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRecipeDefErrors(t *testing.T) {
+	exec := newDefineExecutor(t)
+
+	tests := []struct {
+		name string
+		def  *RecipeDef[definedUser]
+		want error
+	}{
+		{"unknown field", For[definedUser]().Field("Nope", Op("echo")), ErrFieldPath},
+		{"not a struct", For[definedUser]().Field("Name.First", Op("echo")), ErrFieldPath},
+		{"empty path", For[definedUser]().Field("", Op("echo")), ErrFieldPath},
+		{"strategy only", For[definedUser]().Strategy("Name", AllOrNothing), ErrNoOperations},
+		{"unknown grammar", For[definedUser]().Grammar("nope").Field("Name", Op("echo")), ErrGrammarNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.def.Build(exec.builder); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOp(t *testing.T) {
+	got := Op("min", "min=3", "omiterror")
+	want := LazyOperation{Name: "min", Opts: Modifiers{"min": "3", "omiterror": ""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
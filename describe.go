@@ -0,0 +1,210 @@
+package recipe
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RecipeDescription is a stable, exported model of a compiled [Recipe],
+// for inspecting what the [Builder] produced from struct tags.
+type RecipeDescription struct {
+	Type     string  `json:"type"`
+	WalkType string  `json:"walkType"`
+	Arity    OpArity `json:"arity"`
+	Resolved bool    `json:"resolved"`
+	// RegistryVersion is the version of the registry snapshot the recipe
+	// was resolved against, zero if not resolved.
+	RegistryVersion uint64          `json:"registryVersion,omitempty"`
+	Root            NodeDescription `json:"root"`
+}
+
+// NodeDescription describes a single [ExecTree] node.
+type NodeDescription struct {
+	// Path is the dotted path from the root, empty for the root.
+	Path     string            `json:"path"`
+	Name     string            `json:"name"`
+	Type     string            `json:"type,omitempty"`
+	Kind     string            `json:"kind,omitempty"`
+	Offset   uintptr           `json:"offset"`
+	Strategy string            `json:"strategy,omitempty"`
+	Ops      []OpDescription   `json:"ops,omitempty"`
+	Children []NodeDescription `json:"children,omitempty"`
+}
+
+// OpDescription describes a single operation of a node.
+type OpDescription struct {
+	Name string `json:"name"`
+	// Modifiers is nil unless the operation options are [Modifiers].
+	Modifiers map[string]string `json:"modifiers,omitempty"`
+	// Resolved reports whether the operation was found in the registry.
+	Resolved bool `json:"resolved"`
+}
+
+// Describe returns the description of the recipe.
+func (rcp *Recipe) Describe() RecipeDescription {
+	desc := RecipeDescription{
+		WalkType: rcp.WalkType.String(),
+		Arity:    rcp.Arity,
+		Resolved: rcp.resolved,
+	}
+	if rcp.registry != nil {
+		desc.RegistryVersion = rcp.registry.version
+	}
+	if rcp.Type != nil {
+		desc.Type = rcp.Type.String()
+	}
+	if rcp.Root != nil {
+		desc.Root = describeNode(rcp.Root, "")
+	}
+	return desc
+}
+
+func describeNode(eTree *ExecTree, path string) NodeDescription {
+	node := NodeDescription{
+		Path:   path,
+		Name:   eTree.Name,
+		Offset: eTree.fieldOffset,
+	}
+
+	if eTree.fieldType != nil {
+		node.Type = eTree.fieldType.String()
+		node.Kind = eTree.fieldKind.String()
+	}
+
+	if len(eTree.LazyOps) > 0 {
+		node.Strategy = eTree.OpStrategy.String()
+	}
+
+	for i, lazyOp := range eTree.LazyOps {
+		op := OpDescription{
+			Name:     lazyOp.Name,
+			Resolved: i < len(eTree.Operations),
+		}
+		if mods, ok := lazyOp.Opts.(Modifiers); ok && len(mods) > 0 {
+			op.Modifiers = maps.Clone(mods)
+		}
+		node.Ops = append(node.Ops, op)
+	}
+
+	for _, cTree := range eTree.Children {
+		node.Children = append(node.Children, describeNode(cTree, joinPath(path, cTree.Name)))
+	}
+
+	return node
+}
+
+// Text renders the description as an indented tree.
+func (desc RecipeDescription) Text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (%s, arity %d, resolved %t)\n", desc.Type, desc.WalkType, desc.Arity, desc.Resolved)
+	desc.Root.walk(func(node NodeDescription, depth int) {
+		if depth == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "%s%s %s", strings.Repeat("  ", depth), node.Name, node.Type)
+		if len(node.Ops) > 0 {
+			fmt.Fprintf(&sb, " [%s] %s", node.Strategy, node.opsLabel())
+		}
+		sb.WriteByte('\n')
+	})
+	return sb.String()
+}
+
+// JSON renders the description as indented JSON.
+func (desc RecipeDescription) JSON() ([]byte, error) {
+	return json.MarshalIndent(desc, "", "  ")
+}
+
+// DOT renders the description as a Graphviz digraph.
+func (desc RecipeDescription) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph recipe {\n")
+	sb.WriteString("  node [shape=box];\n")
+	desc.Root.walk(func(node NodeDescription, depth int) {
+		fmt.Fprintf(&sb, "  %s [label=%s];\n", dotID(node.Path), strconv.Quote(node.label(desc.Type)))
+		for _, child := range node.Children {
+			fmt.Fprintf(&sb, "  %s -> %s;\n", dotID(node.Path), dotID(child.Path))
+		}
+	})
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the description as a Mermaid flowchart.
+func (desc RecipeDescription) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	desc.Root.walk(func(node NodeDescription, depth int) {
+		label := strings.ReplaceAll(node.label(desc.Type), `"`, "#quot;")
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", dotID(node.Path), strings.ReplaceAll(label, "\n", "<br/>"))
+		for _, child := range node.Children {
+			fmt.Fprintf(&sb, "  %s --> %s\n", dotID(node.Path), dotID(child.Path))
+		}
+	})
+	return sb.String()
+}
+
+// walk visits node and its descendants depth first.
+func (node NodeDescription) walk(visit func(node NodeDescription, depth int)) {
+	var rec func(n NodeDescription, depth int)
+	rec = func(n NodeDescription, depth int) {
+		visit(n, depth)
+		for _, child := range n.Children {
+			rec(child, depth+1)
+		}
+	}
+	rec(node, 0)
+}
+
+// opsLabel renders the operations of a node in tag order, with their
+// value and sorted modifiers, e.g. `email(density=0.8), min=3, hash`.
+func (node NodeDescription) opsLabel() string {
+	ops := make([]string, len(node.Ops))
+	for i, op := range node.Ops {
+		ops[i] = op.Name
+		if v, ok := op.Modifiers[op.Name]; ok {
+			ops[i] += "=" + v
+		}
+
+		mods := make([]string, 0, len(op.Modifiers))
+		for _, k := range slices.Sorted(maps.Keys(op.Modifiers)) {
+			if k == op.Name {
+				continue
+			}
+			if v := op.Modifiers[k]; v != "" {
+				mods = append(mods, k+"="+v)
+			} else {
+				mods = append(mods, k)
+			}
+		}
+		if len(mods) > 0 {
+			ops[i] += "(" + strings.Join(mods, ", ") + ")"
+		}
+	}
+	return strings.Join(ops, ", ")
+}
+
+// label renders a node for the graph renderers.
+func (node NodeDescription) label(rootType string) string {
+	if node.Path == "" {
+		return rootType
+	}
+
+	label := node.Name + ": " + node.Type
+	if len(node.Ops) > 0 {
+		label += "\n" + node.opsLabel()
+	}
+	return label
+}
+
+// dotID returns a graph node identifier for a path.
+func dotID(path string) string {
+	if path == "" {
+		return "root"
+	}
+	return "n_" + strings.ReplaceAll(path, ".", "__")
+}
@@ -0,0 +1,118 @@
+package recipe
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type describedAddress struct {
+	Zip string `mask:"redact,with=***"`
+}
+
+type describedUser struct {
+	Email   string `mask:"email,density=0.5"`
+	Card    string `mask:"card"`
+	Note    string
+	Address describedAddress
+}
+
+func describedRecipe(t *testing.T, resolve bool) *Recipe {
+	t.Helper()
+
+	exec := newMaskExecutor(t, CombineWalk)
+	wt := reflect.TypeFor[describedUser]()
+	if !resolve {
+		rcp, err := exec.builder.GetOrBuild(wt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rcp
+	}
+
+	rcp, err := exec.resolveRecipe(wt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rcp
+}
+
+func TestDescribeText(t *testing.T) {
+	got := describedRecipe(t, true).Describe().Text()
+	want := `recipe.describedUser (CombineWalk, arity 1, resolved true)
+  Email string [FirstSuccess] email(density=0.5)
+  Card string [FirstSuccess] card(keep=4)
+  Address recipe.describedAddress
+    Zip string [FirstSuccess] redact(with=***)
+`
+	if got != want {
+		t.Errorf("described\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDescribeResolution(t *testing.T) {
+	for _, resolve := range []bool{false, true} {
+		desc := describedRecipe(t, resolve).Describe()
+		if desc.Resolved != resolve || (desc.RegistryVersion != 0) != resolve {
+			t.Errorf("resolved %t, version %d, want resolved %t", desc.Resolved, desc.RegistryVersion, resolve)
+		}
+		if op := desc.Root.Children[0].Ops[0]; op.Resolved != resolve {
+			t.Errorf("operation %s resolved %t, want %t", op.Name, op.Resolved, resolve)
+		}
+	}
+}
+
+func TestDescribeJSON(t *testing.T) {
+	desc := describedRecipe(t, true).Describe()
+
+	b, err := desc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got RecipeDescription
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, desc) {
+		t.Errorf("round-tripped %+v, want %+v", got, desc)
+	}
+}
+
+func TestDescribeGraphs(t *testing.T) {
+	desc := describedRecipe(t, true).Describe()
+
+	dot := desc.DOT()
+	for _, want := range []string{
+		"digraph recipe {",
+		`root [label="recipe.describedUser"];`,
+		`n_Email [label="Email: string\nemail(density=0.5)"];`,
+		"root -> n_Address;",
+		"n_Address -> n_Address__Zip;",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT lacks %q:\n%s", want, dot)
+		}
+	}
+
+	mermaid := desc.Mermaid()
+	for _, want := range []string{
+		"flowchart TD",
+		`n_Card["Card: string<br/>card(keep=4)"]`,
+		"n_Address --> n_Address__Zip",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid lacks %q:\n%s", want, mermaid)
+		}
+	}
+}
+
+func TestDescribeOperationValue(t *testing.T) {
+	node := NodeDescription{Ops: []OpDescription{
+		{Name: "min", Modifiers: map[string]string{"min": "3", "msg": "short"}},
+		{Name: "required"},
+	}}
+	if got, want := node.opsLabel(), "min=3(msg=short), required"; got != want {
+		t.Errorf("labeled %q, want %q", got, want)
+	}
+}
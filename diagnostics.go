@@ -0,0 +1,219 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TokenError is a [Grammar] error caused by a single token of a tag.
+//
+// Offset is the byte offset of Token in the string passed to
+// [Grammar.Split], or to [Grammar.Parse], negative if unknown. The
+// builder reports it in the tag, see [Diagnostic]. If Token is not found
+// at Offset, its first occurrence in the tag is reported instead.
+type TokenError struct {
+	Token  string
+	Offset int
+	// Hint is a suggestion to fix the token, if any.
+	Hint string
+	Err  error
+}
+
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("token %q: %v", e.Token, e.Err)
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// Diagnostic is a problem with the tag of a single field.
+type Diagnostic struct {
+	// Path is the dotted path of the field, e.g. "Address.Zip"
+	Path string
+	// Tag is the raw tag value of the field.
+	Tag string
+	// Offset is the byte offset in Tag of the offending token,
+	// -1 if unknown.
+	Offset int
+	// Op is the offending token or operation, if known.
+	Op string
+	// Suggestion to fix the problem, if any.
+	Suggestion string
+	Err        error
+}
+
+func (d Diagnostic) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %v", d.Path, d.Err)
+	if d.Offset >= 0 {
+		fmt.Fprintf(&sb, " (at offset %d of `%s`)", d.Offset, d.Tag)
+	}
+	if d.Suggestion != "" {
+		fmt.Fprintf(&sb, "; %s", d.Suggestion)
+	}
+	return sb.String()
+}
+
+func (d Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+// BuildDiagnostics is every tag problem found while building or resolving
+// a recipe, in field order.
+//
+// Returned wrapped by [Builder.GetOrBuild] and recipe resolution,
+// retrieve it with errors.As.
+type BuildDiagnostics []Diagnostic
+
+func (ds BuildDiagnostics) Error() string {
+	if len(ds) == 1 {
+		return ds[0].Error()
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d tag problems:", len(ds))
+	for _, d := range ds {
+		sb.WriteString("\n\t")
+		sb.WriteString(d.Error())
+	}
+	return sb.String()
+}
+
+func (ds BuildDiagnostics) Unwrap() []error {
+	errs := make([]error, len(ds))
+	for i, d := range ds {
+		errs[i] = d
+	}
+	return errs
+}
+
+// err returns ds as an error, or nil if empty.
+func (ds BuildDiagnostics) err() error {
+	if len(ds) == 0 {
+		return nil
+	}
+	return ds
+}
+
+// newDiagnostic describes err, raised by the tag of the field at path.
+func newDiagnostic(path, tag string, err error) Diagnostic {
+	d := Diagnostic{Path: path, Tag: tag, Offset: -1, Err: err}
+
+	var te *TokenError
+	if errors.As(err, &te) {
+		d.Op = te.Token
+		d.Offset = te.Offset
+		if d.Offset < 0 || d.Offset > len(tag) || !strings.HasPrefix(tag[d.Offset:], te.Token) {
+			d.Offset = strings.Index(tag, te.Token)
+		}
+		d.Suggestion = te.Hint
+	}
+
+	return d
+}
+
+// shiftTokenError adds off, the offset of the string a [TokenError] in err
+// was raised for, to its offset. A negative off makes its offset unknown.
+func shiftTokenError(err error, off int) error {
+	var te *TokenError
+	if errors.As(err, &te) && te.Offset >= 0 {
+		te.Offset += off
+		if off < 0 {
+			te.Offset = -1
+		}
+	}
+	return err
+}
+
+// opDiagnostic describes err, raised while resolving the operation lazyOp
+// of the field at path, tagged tag.
+func opDiagnostic(path, tag string, lazyOp LazyOperation, err error) Diagnostic {
+	return Diagnostic{Path: path, Tag: tag, Offset: opOffset(tag, lazyOp.Name), Op: lazyOp.Name, Err: err}
+}
+
+// opOffset returns the offset of the first token of tag keyed name, e.g.
+// `min` in `admin,min=3`, or -1 if none.
+func opOffset(tag, name string) int {
+	for i := 0; name != "" && i+len(name) <= len(tag); i++ {
+		j := strings.Index(tag[i:], name)
+		if j < 0 {
+			break
+		}
+		i += j
+
+		before := i == 0 || !isIdentByte(tag[i-1])
+		after := i+len(name) == len(tag) || !isIdentByte(tag[i+len(name)])
+		if before && after {
+			return i
+		}
+	}
+	return -1
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// suggestUnknownOp suggests the closest of the operation names, or modifier
+// of the previous operation, for the unknown operation lazyOps[i].
+func suggestUnknownOp(grammar Grammar, names []string, lazyOps []LazyOperation, i int) string {
+	name := lazyOps[i].Name
+	key := tokenKey(name)
+
+	// Unknown modifiers are split as operations by flat grammars
+	if i > 0 {
+		if mk, ok := grammar.(modifierLister); ok {
+			prev := tokenKey(lazyOps[i-1].Name)
+			if mod := closest(key, mk.modifierKeys(prev)); mod != "" {
+				return fmt.Sprintf("unknown modifier `%s`, did you mean `%s`?", key, mod)
+			}
+		}
+	}
+
+	if op := closest(name, names); op != "" {
+		return fmt.Sprintf("unknown operation `%s`, did you mean `%s`?", name, op)
+	}
+	return ""
+}
+
+// modifierLister is implemented by grammars that know the modifier keys
+// of their operations.
+type modifierLister interface {
+	modifierKeys(opkey string) []string
+}
+
+// closest returns the candidate within a small edit distance of s, or "".
+func closest(s string, candidates []string) string {
+	best, bestDist := "", max(2, len(s)/3)+1
+	for _, c := range candidates {
+		if d := editDistance(s, c); d < bestDist && c != s {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,124 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func newDiagnosticGrammar(t *testing.T) Grammar {
+	t.Helper()
+
+	grammar, err := NewGrammarConfig().
+		SetKey("v").
+		SetWalkType(CombineWalk).
+		SetCombiner(BoolAndCombiner{}).
+		SetOpArity(OpUnary).
+		SetArity(GrammarArityVariadic).
+		SetModifierFormat(ModFormatMixed).
+		SetSharedModifier("msg", ModifierUseOperation, ModKindString).
+		SetSharedModifier("n", ModifierUseOperation, ModKindInt).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grammar
+}
+
+func TestDiagnosticOffset(t *testing.T) {
+	grammar := newDiagnosticGrammar(t)
+
+	tests := []struct {
+		name   string
+		tag    string
+		token  string
+		offset int
+	}{
+		{"first token", "msg", "msg", 0},
+		{"repeated token", "min=3,msg,max=3,msg", "msg", 6},
+		{"repeated valid token", "min=3,msg=a,max=3,msg", "msg", 18},
+		{"substring of another token", "fn=x,max=1,n=x", "n=x", 11},
+		{"after spaces", "min=3,   n=x", "n=x", 9},
+		{"unterminated quote", "min=3,msg='a", "msg='a", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTag(grammar, tt.tag)
+			if err == nil {
+				t.Fatal("parsed invalid tag")
+			}
+
+			d := newDiagnostic("F", tt.tag, err)
+			if d.Op != tt.token || d.Offset != tt.offset {
+				t.Errorf("got %q at %d, want %q at %d", d.Op, d.Offset, tt.token, tt.offset)
+			}
+		})
+	}
+}
+
+func TestDiagnosticOffsetFallback(t *testing.T) {
+	// Grammars that do not know the offset of a token report it anyway
+	err := &TokenError{Token: "b", Err: ErrTagMalformed}
+	if d := newDiagnostic("F", "a,b", err); d.Offset != 2 {
+		t.Errorf("got offset %d, want 2", d.Offset)
+	}
+
+	err = &TokenError{Token: "c", Offset: -1, Err: ErrTagMalformed}
+	if d := newDiagnostic("F", "a,b", err); d.Offset != -1 {
+		t.Errorf("got offset %d, want -1", d.Offset)
+	}
+}
+
+func TestOpOffset(t *testing.T) {
+	tests := []struct {
+		tag, name string
+		want      int
+	}{
+		{"min=3", "min", 0},
+		{"admin,min=3", "min", 6},
+		{"minimum,min", "min", 8},
+		{"required", "min", -1},
+		{"", "min", -1},
+	}
+
+	for _, tt := range tests {
+		if got := opOffset(tt.tag, tt.name); got != tt.want {
+			t.Errorf("opOffset(%q, %q) = %d, want %d", tt.tag, tt.name, got, tt.want)
+		}
+	}
+}
+
+type diagnosed struct {
+	A string `v:"min=3,msg,max=3,msg"`
+	B string `v:"required"`
+	C string `v:"fn=x,n=x"`
+}
+
+func TestBuildDiagnostics(t *testing.T) {
+	b := NewBuilder(newDiagnosticGrammar(t))
+
+	_, err := b.GetOrBuild(reflect.TypeFor[diagnosed]())
+	var diags BuildDiagnostics
+	if !errors.As(err, &diags) {
+		t.Fatalf("got %v, want BuildDiagnostics", err)
+	}
+
+	want := []struct {
+		path   string
+		offset int
+	}{{"A", 6}, {"C", 5}}
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, w := range want {
+		if diags[i].Path != w.path || diags[i].Offset != w.offset {
+			t.Errorf("diagnostic %d at %s:%d, want %s:%d", i, diags[i].Path, diags[i].Offset, w.path, w.offset)
+		}
+		if !errors.Is(diags[i], ErrModInvalid) {
+			t.Errorf("diagnostic %d: got %v, want %v", i, diags[i].Err, ErrModInvalid)
+		}
+	}
+}
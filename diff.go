@@ -0,0 +1,184 @@
+package recipe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// DiffGrammarKey is the struct tag key read by [NewDiffGrammar].
+//
+// e.g., `diff:"nocase"`, `diff:"tolerance=0.001"`, `diff:"-"`
+const DiffGrammarKey = "diff"
+
+// DiffOpEqual is the operation implied by every `diff` tag, and applied
+// to untagged fields.
+const DiffOpEqual = "eq"
+
+// Diff modifier keys.
+const (
+	// DiffModNoCase compares strings case-insensitively.
+	DiffModNoCase = "nocase"
+	// DiffModTolerance compares floats within an absolute tolerance.
+	DiffModTolerance = "tolerance"
+)
+
+// FieldChange is a field whose value differs between two structs.
+type FieldChange struct {
+	// Path is the dotted path of the field, e.g. "Address.Zip"
+	Path string
+	Old  any
+	New  any
+}
+
+// ChangeListCombiner collects [FieldChange] results into a []FieldChange,
+// in field order.
+type ChangeListCombiner struct{}
+
+var __ctc__ChangeListCombiner_impl_FieldCombiner FieldCombiner = ChangeListCombiner{}
+
+func (c ChangeListCombiner) Zero() any { return []FieldChange(nil) }
+func (c ChangeListCombiner) Combine(acc, result any) any {
+	return append(acc.([]FieldChange), result.([]FieldChange)...)
+}
+func (c ChangeListCombiner) CombineField(acc any, path string, result any) any {
+	change, ok := result.(FieldChange)
+	if !ok {
+		return acc
+	}
+	change.Path = path
+	return append(acc.([]FieldChange), change)
+}
+
+// NewDiffGrammar returns the arity-2 [CombineWalk] grammar for `diff` tags.
+//
+// Every exported field is compared, fields tagged `-` are ignored.
+func NewDiffGrammar() (Grammar, error) {
+	return NewGrammarConfig().
+		SetKey(DiffGrammarKey).
+		SetDescription("Compares two structs of the same type field by field").
+		SetWalkType(CombineWalk).
+		SetCombiner(ChangeListCombiner{}).
+		SetOpArity(OpBinary).
+		SetArity(GrammarArityUnary).
+		SetModifierFormat(ModFormatMixed).
+		SetDefaultOperation(DiffOpEqual).
+		SetImplicitOperation(DiffOpEqual).
+		SetDescribedOperation(DiffOpEqual, DiffEqual{}).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+}
+
+// RegisterDiffOperations registers the [DiffOpEqual] operation.
+//
+// Returns the registration conflict, if any, see
+// [OpRegistry.RegisterOperation].
+func RegisterDiffOperations(reg *OpRegistry) error {
+	return reg.RegisterOperation(DiffOpEqual, DiffEqual{})
+}
+
+// Diff returns the changes from old to new, in field order.
+//
+// exec must be built from a [NewDiffGrammar].
+func Diff[T any](exec *Executor, old, new *T) ([]FieldChange, error) {
+	return DiffWith(exec, nil, old, new)
+}
+
+// DiffWith is [Diff] under ctx, e.g. to select the diff grammar with
+// [ExecContext.Grammar] when exec has several grammars.
+//
+// ctx must not override the combiner with one that does not combine
+// []FieldChange.
+func DiffWith[T any](exec *Executor, ctx *ExecContext, old, new *T) ([]FieldChange, error) {
+	res, err := exec.ExecuteCombineWalk(ctx, []any{old, new})
+	if err != nil {
+		return nil, fmt.Errorf("diffing %T: %w", old, err)
+	}
+
+	changes, ok := res.([]FieldChange)
+	if !ok {
+		return nil, fmt.Errorf("%w: diff combined %T, want []FieldChange", ErrOpMismatch, res)
+	}
+	return changes, nil
+}
+
+// DiffEqual compares the old and new value of a field.
+//
+// Returns a [FieldChange] if they differ, nil otherwise. Values with an
+// `Equal(T) bool` method, such as time.Time, are compared with it.
+type DiffEqual struct{}
+
+var __ctc__DiffEqual_impl_DescribedOperation DescribedOperation = DiffEqual{}
+
+func (DiffEqual) Arity() OpArity { return OpBinary }
+func (DiffEqual) Doc() OperationDoc {
+	return OperationDoc{
+		Description: "Compares the old and new value of a field, returning a FieldChange if they differ.",
+		Modifiers: []ModifierSpec{
+			NewModifierSpec(DiffModNoCase, ModifierUseOperation, ModKindBool),
+			NewModifierSpec(DiffModTolerance, ModifierUseOperation, ModKindFloat),
+		},
+		Output:   reflect.TypeFor[FieldChange](),
+		Examples: []string{`diff:"nocase"`, `diff:"tolerance=0.001"`, `diff:"-"`},
+	}
+}
+func (DiffEqual) Execute(opts OpOpts, sources ...any) (any, error) {
+	if len(sources) != 2 {
+		return nil, fmt.Errorf("%w: eq expects 2 sources, got %d", ErrOpMismatch, len(sources))
+	}
+
+	equal, err := diffEqual(opts, sources[0], sources[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if equal {
+		return nil, nil
+	}
+	return FieldChange{Old: sources[0], New: sources[1]}, nil
+}
+
+func diffEqual(opts OpOpts, a, b any) (bool, error) {
+	if modBool(opts, DiffModNoCase) {
+		as, aok := a.(string)
+		bs, bok := b.(string)
+		if !aok || !bok {
+			return false, fmt.Errorf("%w: %s expects strings, got %T and %T", ErrOpMismatch, DiffModNoCase, a, b)
+		}
+		return strings.EqualFold(as, bs), nil
+	}
+
+	if modString(opts, DiffModTolerance, "") != "" {
+		tol, err := modFloat(opts, DiffModTolerance, 0)
+		if err != nil {
+			return false, err
+		}
+
+		av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+		if !av.CanFloat() || !bv.CanFloat() {
+			return false, fmt.Errorf("%w: %s expects floats, got %T and %T", ErrOpMismatch, DiffModTolerance, a, b)
+		}
+		return math.Abs(av.Float()-bv.Float()) <= tol, nil
+	}
+
+	if a == nil || b == nil {
+		return a == b, nil
+	}
+
+	// Nil pointers equal each other only, and have no methods to call
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if av.Kind() == reflect.Pointer && bv.Kind() == reflect.Pointer && (av.IsNil() || bv.IsNil()) {
+		return av.IsNil() && bv.IsNil(), nil
+	}
+
+	if eq := av.MethodByName("Equal"); eq.IsValid() {
+		t := eq.Type()
+		if t.NumIn() == 1 && t.NumOut() == 1 && t.Out(0).Kind() == reflect.Bool && reflect.TypeOf(b).AssignableTo(t.In(0)) {
+			return eq.Call([]reflect.Value{reflect.ValueOf(b)})[0].Bool(), nil
+		}
+	}
+
+	return reflect.DeepEqual(a, b), nil
+}
@@ -0,0 +1,130 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type diffAddress struct {
+	City string
+	Zip  string `diff:"-"`
+}
+
+type diffUser struct {
+	Name    string  `diff:"nocase"`
+	Score   float64 `diff:"tolerance=0.01"`
+	Age     int
+	Nick    *string
+	Seen    time.Time
+	Address diffAddress
+	Ignored string `diff:"-"`
+}
+
+func newDiffExecutor(t *testing.T) *Executor {
+	t.Helper()
+
+	grammar, err := NewDiffGrammar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := NewOpRegistry()
+	if err := RegisterDiffOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	return NewExecutor(reg, NewBuilder(grammar))
+}
+
+func TestDiff(t *testing.T) {
+	exec := newDiffExecutor(t)
+
+	x, x2, y := "x", "x", "y"
+	now := time.Now()
+	base := diffUser{Name: "Ada", Score: 1, Age: 36, Nick: &x, Seen: now, Address: diffAddress{City: "London", Zip: "N1"}}
+
+	tests := []struct {
+		name   string
+		modify func(u *diffUser)
+		want   []FieldChange
+	}{
+		{"equal", func(u *diffUser) {}, nil},
+		{"case only", func(u *diffUser) { u.Name = "ADA" }, nil},
+		{"within tolerance", func(u *diffUser) { u.Score = 1.005 }, nil},
+		{"same pointee", func(u *diffUser) { u.Nick = &x2 }, nil},
+		{"same instant", func(u *diffUser) { u.Seen = now.UTC() }, nil},
+		{"ignored", func(u *diffUser) { u.Ignored, u.Address.Zip = "z", "E1" }, nil},
+		{"int", func(u *diffUser) { u.Age = 37 }, []FieldChange{{Path: "Age", Old: 36, New: 37}}},
+		{"pointee", func(u *diffUser) { u.Nick = &y }, []FieldChange{{Path: "Nick", Old: &x, New: &y}}},
+		{"to nil", func(u *diffUser) { u.Nick = nil }, []FieldChange{{Path: "Nick", Old: &x, New: (*string)(nil)}}},
+		{"nested", func(u *diffUser) { u.Address.City = "Paris" }, []FieldChange{{Path: "Address.City", Old: "London", New: "Paris"}}},
+		{"several", func(u *diffUser) { u.Name, u.Age = "Bob", 1 }, []FieldChange{
+			{Path: "Name", Old: "Ada", New: "Bob"},
+			{Path: "Age", Old: 36, New: 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old, new := base, base
+			tt.modify(&new)
+
+			got, err := Diff(exec, &old, &new)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffed %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffNilPointers(t *testing.T) {
+	exec := newDiffExecutor(t)
+
+	old, new := diffUser{}, diffUser{}
+	got, err := Diff(exec, &old, &new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("diffed %+v, want no changes", got)
+	}
+
+	x := "x"
+	new.Nick = &x
+	got, err = Diff(exec, &old, &new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FieldChange{{Path: "Nick", Old: (*string)(nil), New: &x}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffed %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffEqualMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Modifiers
+		a, b any
+		want string
+	}{
+		{"nocase", Modifiers{DiffModNoCase: ""}, "a", 1, "nocase expects strings, got string and int"},
+		{"tolerance", Modifiers{DiffModTolerance: "0.1"}, "a", 1.0, "tolerance expects floats, got string and float64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := diffEqual(tt.opts, tt.a, tt.b)
+			if !errors.Is(err, ErrOpMismatch) {
+				t.Fatalf("diffEqual returned %v, want ErrOpMismatch", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("diffEqual returned %q, want it to report %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package recipe
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+var (
+	ErrEnvRequired = fmt.Errorf("required environment variable not set")
+	ErrEnvNoName   = fmt.Errorf("environment binding has no name")
+)
+
+// EnvGrammarKey is the struct tag key read by [NewEnvGrammar].
+//
+// e.g., `env:"name=DB_HOST,default=localhost,required"`
+const EnvGrammarKey = "env"
+
+// EnvOpName is the operation implied by every `env` tag.
+const EnvOpName = "env"
+
+// Env modifier keys.
+const (
+	// EnvModName is the variable name, relative to enclosing prefixes.
+	EnvModName = "name"
+	// EnvModDefault is used when the variable is not set.
+	EnvModDefault = "default"
+	// EnvModRequired fails the binding when the variable is not set and
+	// has no default.
+	EnvModRequired = "required"
+	// EnvModSep splits the variable into a slice.
+	// Defaults to [DefaultSliceSeparator].
+	EnvModSep = "sep"
+	// EnvModPrefix is prepended to the names of every field of the tagged
+	// struct. Prefixes of nested structs accumulate.
+	EnvModPrefix = "prefix"
+)
+
+// EnvSource looks up environment variables by name.
+//
+// See: [OSEnv], [MapEnv]
+type EnvSource interface {
+	Lookup(name string) (string, bool)
+}
+
+// OSEnv looks up variables in the process environment.
+type OSEnv struct{}
+
+func (OSEnv) Lookup(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+// MapEnv looks up variables in a map, e.g. for tests.
+type MapEnv map[string]string
+
+func (m MapEnv) Lookup(name string) (string, bool) {
+	v, ok := m[name]
+	return v, ok
+}
+
+// prefixEnv prepends prefix to every name looked up in src.
+type prefixEnv struct {
+	src    EnvSource
+	prefix string
+}
+
+func (p prefixEnv) Lookup(name string) (string, bool) {
+	return p.src.Lookup(p.prefix + name)
+}
+
+var (
+	__ctc__OSEnv_impl_EnvSource     EnvSource = OSEnv{}
+	__ctc__MapEnv_impl_EnvSource    EnvSource = MapEnv{}
+	__ctc__prefixEnv_impl_EnvSource EnvSource = prefixEnv{}
+)
+
+// NewEnvGrammar returns the [ApplyWalk] grammar for `env` tags.
+//
+// Every tag is a list of [EnvOpName] modifiers. On a nested struct field,
+// `env:"prefix=DB_"` prefixes the names of all its fields.
+func NewEnvGrammar() (Grammar, error) {
+	return NewGrammarConfig().
+		SetKey(EnvGrammarKey).
+		SetDescription("Binds struct fields from environment variables").
+		SetWalkType(ApplyWalk).
+		SetApplier(ReflectSetterApplier{}).
+		SetOpArity(OpUnary).
+		SetArity(GrammarArityUnary).
+		SetModifierFormat(ModFormatMixed).
+		SetDefaultOperation(EnvOpName).
+		SetDescribedOperation(EnvOpName, EnvBind{}).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+}
+
+// RegisterEnvOperations registers the [EnvOpName] operation.
+//
+// Returns the registration conflict, if any, see
+// [OpRegistry.RegisterOperation].
+func RegisterEnvOperations(reg *OpRegistry) error {
+	return reg.RegisterOperation(EnvOpName, EnvBind{})
+}
+
+// BindEnv fills the `env` tagged fields of dst, a pointer to a struct,
+// from src. A nil src reads the process environment.
+//
+// exec must be built from a [NewEnvGrammar]. Unset variables without
+// default leave their field untouched.
+func BindEnv(exec *Executor, dst any, src EnvSource) error {
+	return BindEnvWith(exec, nil, dst, src)
+}
+
+// BindEnvWith is [BindEnv] under ctx, e.g. to select the env grammar with
+// [ExecContext.Grammar] when exec has several grammars.
+func BindEnvWith(exec *Executor, ctx *ExecContext, dst any, src EnvSource) error {
+	if src == nil {
+		src = OSEnv{}
+	}
+
+	err := exec.ExecuteApplyWalk(ctx, []any{dst}, []any{src})
+	if err != nil {
+		return fmt.Errorf("binding environment: %w", err)
+	}
+	return nil
+}
+
+// EnvBind resolves a single variable from an [EnvSource].
+//
+// Returns the raw string (or []string with [EnvModSep]), which the
+// [ReflectSetterApplier] parses into the field type. With [EnvModPrefix],
+// returns the prefixed [EnvSource] for the children of a struct field.
+type EnvBind struct{}
+
+var __ctc__EnvBind_impl_DescribedOperation DescribedOperation = EnvBind{}
+
+func (EnvBind) Arity() OpArity { return OpUnary }
+func (EnvBind) Doc() OperationDoc {
+	return OperationDoc{
+		Description: "Binds a field from an environment variable, or prefixes the variables of a struct field's children.",
+		Modifiers: []ModifierSpec{
+			NewModifierSpec(EnvModName, ModifierUseOperation, ModKindString),
+			NewModifierSpec(EnvModDefault, ModifierUseOperation, ModKindString),
+			NewModifierSpec(EnvModRequired, ModifierUseOperation, ModKindBool),
+			NewModifierSpec(EnvModSep, ModifierUseOperation, ModKindString),
+			NewModifierSpec(EnvModPrefix, ModifierUseOperation, ModKindString),
+		},
+		Input:    reflect.TypeFor[EnvSource](),
+		Examples: []string{`env:"name=DB_HOST,default=localhost,required"`, `env:"prefix=DB_"`},
+	}
+}
+func (EnvBind) Execute(opts OpOpts, sources ...any) (any, error) {
+	if len(sources) != 1 {
+		return nil, fmt.Errorf("%w: env expects 1 source, got %d", ErrOpMismatch, len(sources))
+	}
+
+	src, ok := sources[0].(EnvSource)
+	if !ok {
+		return nil, fmt.Errorf("%w: env expects EnvSource, got %T", ErrOpMismatch, sources[0])
+	}
+
+	if prefix, ok := opts.Modifier(EnvModPrefix); ok {
+		return prefixEnv{src: src, prefix: prefix}, nil
+	}
+
+	name := modString(opts, EnvModName, "")
+	if name == "" {
+		return nil, ErrEnvNoName
+	}
+
+	val, ok := src.Lookup(name)
+	if !ok {
+		val, ok = opts.Modifier(EnvModDefault)
+	}
+	if !ok {
+		if modBool(opts, EnvModRequired) {
+			return nil, fmt.Errorf("%w: %s", ErrEnvRequired, envName(src, name))
+		}
+		return nil, nil
+	}
+
+	if sep, ok := opts.Modifier(EnvModSep); ok && sep != "" {
+		if val == "" {
+			return []string{}, nil
+		}
+		return strings.Split(val, sep), nil
+	}
+
+	return val, nil
+}
+
+// envName returns the fully prefixed name of a variable, for errors.
+func envName(src EnvSource, name string) string {
+	for {
+		p, ok := src.(prefixEnv)
+		if !ok {
+			return name
+		}
+		name = p.prefix + name
+		src = p.src
+	}
+}
@@ -0,0 +1,111 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type envDB struct {
+	Host string `env:"name=HOST,default=localhost"`
+	Port int    `env:"name=PORT,required"`
+}
+
+type envConfig struct {
+	Debug   bool          `env:"name=DEBUG"`
+	Timeout time.Duration `env:"name=TIMEOUT,default=5s"`
+	Tags    []string      `env:"name=TAGS,sep=;"`
+	DB      envDB         `env:"prefix=DB_"`
+	Unbound string
+}
+
+func newEnvExecutor(t *testing.T) *Executor {
+	t.Helper()
+
+	grammar, err := NewEnvGrammar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := NewOpRegistry()
+	if err := RegisterEnvOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	return NewExecutor(reg, NewBuilder(grammar))
+}
+
+func TestBindEnv(t *testing.T) {
+	exec := newEnvExecutor(t)
+
+	tests := []struct {
+		name string
+		env  MapEnv
+		want envConfig
+		err  error
+	}{
+		{
+			name: "all set",
+			env:  MapEnv{"DEBUG": "true", "TIMEOUT": "1m", "TAGS": "a;b", "DB_HOST": "db", "DB_PORT": "5432"},
+			want: envConfig{Debug: true, Timeout: time.Minute, Tags: []string{"a", "b"}, DB: envDB{Host: "db", Port: 5432}, Unbound: "kept"},
+		},
+		{
+			name: "defaults",
+			env:  MapEnv{"DB_PORT": "5432"},
+			want: envConfig{Timeout: 5 * time.Second, DB: envDB{Host: "localhost", Port: 5432}, Unbound: "kept"},
+		},
+		{
+			name: "required missing",
+			env:  MapEnv{},
+			err:  ErrEnvRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := envConfig{Unbound: "kept"}
+			err := BindEnv(exec, &cfg, tt.env)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg, tt.want) {
+				t.Errorf("bound %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindEnvMalformed(t *testing.T) {
+	exec := newEnvExecutor(t)
+
+	cfg := envConfig{DB: envDB{Port: 8080}}
+	err := BindEnv(exec, &cfg, MapEnv{"DB_PORT": "abc"})
+	if !errors.Is(err, ErrParseValue) {
+		t.Fatalf("got %v, want %v", err, ErrParseValue)
+	}
+	if cfg.DB.Port != 8080 {
+		t.Errorf("malformed value overwrote the field with %d", cfg.DB.Port)
+	}
+
+	// Nor are fields of other kinds set, e.g. to a clamped value
+	var v struct {
+		B bool
+		U uint8
+		F float32
+	}
+	v.B, v.U, v.F = true, 7, 1.5
+	rv := reflect.ValueOf(&v).Elem()
+	for i, s := range []string{"maybe", "300", "1e40"} {
+		if err := parseInto(rv.Field(i), s); !errors.Is(err, ErrParseValue) {
+			t.Errorf("%q: got %v, want %v", s, err, ErrParseValue)
+		}
+	}
+	if v.B != true || v.U != 7 || v.F != 1.5 {
+		t.Errorf("malformed values overwrote the fields: %+v", v)
+	}
+}
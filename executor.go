@@ -1,10 +1,11 @@
 package recipe
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 	"unsafe"
-	// "unsafe"
 )
 
 var (
@@ -14,15 +15,43 @@ var (
 )
 
 type Executor struct {
-	reg     *OpRegistry
+	// reg is swapped by [Executor.SwapRegistry].
+	reg     atomic.Pointer[OpRegistry]
 	builder *Builder
+
+	// parallelism is the default number of concurrent fields, see [Executor.SetParallelism].
+	parallelism int
 }
 
 func NewExecutor(registry *OpRegistry, builder *Builder) *Executor {
-	return &Executor{
-		reg:     registry,
+	exec := &Executor{
 		builder: builder,
 	}
+	exec.reg.Store(registry)
+	return exec
+}
+
+// Run executes the recipe of the grammar with the given key on walked,
+// with the walk type of that grammar.
+//
+// Walks needing values or an [ExecContext] go through [Executor.Execute],
+// with [ExecContext.Grammar] set to key.
+func (exec *Executor) Run(key string, walked ...any) (any, error) {
+	if len(walked) == 0 {
+		return nil, fmt.Errorf("no walked arguments provided")
+	}
+
+	wet, err := exec.elemType(reflect.TypeOf(walked[0]))
+	if err != nil {
+		return nil, fmt.Errorf("resolving elem of walked type: %w", err)
+	}
+
+	rcp, err := exec.resolveRecipeWith(key, wet)
+	if err != nil {
+		return nil, fmt.Errorf("resolving recipe: %w", err)
+	}
+
+	return exec.Execute(&ExecContext{Grammar: key}, rcp.WalkType, walked, nil)
 }
 
 func (exec *Executor) Execute(ctx *ExecContext, wt WalkType, walked []any, values []any) (any, error) {
@@ -31,8 +60,8 @@ func (exec *Executor) Execute(ctx *ExecContext, wt WalkType, walked []any, value
 		return exec.ExecuteCombineWalk(ctx, walked)
 	case ApplyWalk:
 		return nil, exec.ExecuteApplyWalk(ctx, walked, values)
-		// case TransformWalk:
-		// 	return nil, exec.ExecuteTransformWalk(ctx, walked)
+	case TransformWalk:
+		return nil, exec.ExecuteTransformWalk(ctx, walked)
 	default:
 		return nil, fmt.Errorf("unknown walk type %d", wt)
 	}
@@ -48,7 +77,12 @@ func (exec *Executor) prepareExecute(ctx *ExecContext, wt WalkType, walked []any
 		return nil, fmt.Errorf("resolving elem of walked type: %w", err)
 	}
 
-	rcp, err := exec.resolveRecipe(wet)
+	key := ""
+	if ctx != nil {
+		key = ctx.Grammar
+	}
+
+	rcp, err := exec.resolveRecipeWith(key, wet)
 	if err != nil {
 		return nil, fmt.Errorf("resolving recipe: %w", err)
 	}
@@ -57,7 +91,7 @@ func (exec *Executor) prepareExecute(ctx *ExecContext, wt WalkType, walked []any
 		return nil, ErrWalkTypeMismatch
 	}
 
-	err = exec.applyContext(ctx, rcp)
+	rcp, err = exec.applyContext(ctx, rcp)
 	if err != nil {
 		return nil, fmt.Errorf("applying exec context: %w", err)
 	}
@@ -82,82 +116,149 @@ func (exec *Executor) prepareExecute(ctx *ExecContext, wt WalkType, walked []any
 	return rcp, nil
 }
 
-// applyContent configures a recipe with some set of opinionated behavior,
+// applyContext configures a recipe with some set of opinionated behavior,
 // on a per-call basis to [Executor.Execute].
 //
-// WARNING: This must always be called AFTER recipe resolution,
-// so as to not taint the cached recipe in the builder.
-func (exec *Executor) applyContext(ctx *ExecContext, rcp *Recipe) error {
+// Returns rcp, or a copy of it with the overrides of ctx applied: the
+// resolved recipe is shared by every call, and must not be modified.
+func (exec *Executor) applyContext(ctx *ExecContext, rcp *Recipe) (*Recipe, error) {
 	if ctx == nil {
-		return nil
+		return rcp, nil
 	}
 
-	switch rcp.WalkType {
-	case CombineWalk:
-		if ctx.CombinerOverride != nil {
-			rcp.combiner = ctx.CombinerOverride
-		}
-	case ApplyWalk:
-		if ctx.ApplierOverride != nil {
-			rcp.applier = ctx.ApplierOverride
-		}
-	case TransformWalk:
-		if ctx.TransformerOverride != nil {
-			rcp.transformer = ctx.TransformerOverride
-		}
+	switch {
+	case rcp.WalkType == CombineWalk && ctx.CombinerOverride != nil:
+		cp := *rcp
+		cp.combiner = ctx.CombinerOverride
+		return &cp, nil
+	case rcp.WalkType == ApplyWalk && ctx.ApplierOverride != nil:
+		cp := *rcp
+		cp.applier = ctx.ApplierOverride
+		return &cp, nil
+	case rcp.WalkType == TransformWalk && ctx.TransformerOverride != nil:
+		cp := *rcp
+		cp.transformer = ctx.TransformerOverride
+		return &cp, nil
 	}
 
-	return nil
+	return rcp, nil
 }
 
-// resolveRecipe ensures that the recipe for the given type is built and resolved.
+// resolveRecipe ensures that the recipe for the given type is built and
+// resolved against the current version of the registry.
 //
 // Takes reflect.TypeOf(Walked).Elem() as input, where Walked is a valid pointer to struct.
 func (exec *Executor) resolveRecipe(wet reflect.Type) (*Recipe, error) {
-	rcp, err := exec.builder.GetOrBuild(wet)
+	return exec.resolveRecipeWith("", wet)
+}
+
+// resolveRecipeWith is resolveRecipe for the grammar of the given key,
+// or the default grammar if key is empty.
+func (exec *Executor) resolveRecipeWith(key string, wet reflect.Type) (*Recipe, error) {
+	rcp, err := exec.builder.GetOrBuildWith(key, wet)
 	if err != nil {
 		return nil, err
 	}
 
-	if !rcp.resolved {
-		err := exec.resolveTree(rcp.Root, rcp.Arity)
-		if err != nil {
-			return nil, fmt.Errorf("resolving exec tree: %w", err)
-		}
+	snap := exec.reg.Load().Snapshot()
+	if rcp.resolved && rcp.registry == snap {
+		return rcp, nil
+	}
+
+	resolved, err := exec.resolveAgainst(rcp, snap)
+	if err != nil {
+		return nil, err
 	}
 
-	rcp.resolved = true
+	exec.builder.replace(recipeKey{rcp.Grammar, rcp.Type}, rcp, resolved)
+	return resolved, nil
+}
 
-	err = exec.builder.Set(wet, rcp)
+// resolveAgainst returns a copy of rcp resolved against the registry
+// snapshot snap. Cached recipes are never resolved in place, as they may
+// be walked concurrently.
+func (exec *Executor) resolveAgainst(rcp *Recipe, snap *OpRegistry) (*Recipe, error) {
+	grammar, err := exec.builder.Grammar(rcp.Grammar)
 	if err != nil {
 		return nil, err
 	}
 
-	return rcp, nil
+	resolved := rcp.unresolved()
+
+	var diags BuildDiagnostics
+	exec.resolveTree(snap, grammar, resolved.Root, resolved.Arity, "", &diags)
+	if err := diags.err(); err != nil {
+		return nil, fmt.Errorf("resolving exec tree: %w", err)
+	}
+
+	resolved.generated = bindGenerated(resolved)
+	resolved.plan = compileCombinePlan(resolved)
+	resolved.registry = snap
+	resolved.resolved = true
+
+	return resolved, nil
 }
 
-func (exec *Executor) resolveTree(eTree *ExecTree, arity OpArity) error {
-	for _, lazyOp := range eTree.LazyOps {
-		rOp, err := exec.reg.resolveOperation(lazyOp)
+// resolveTree resolves the operations of eTree, at path from the root,
+// and of its children, from reg.
+//
+// Unresolvable operations are reported in diags.
+func (exec *Executor) resolveTree(reg *OpRegistry, grammar Grammar, eTree *ExecTree, arity OpArity, path string, diags *BuildDiagnostics) {
+	// Macros expand in place, once, into the operations they stand for
+	lazyOps, err := reg.expandMacros(grammar, eTree.LazyOps)
+	if err != nil {
+		*diags = append(*diags, newDiagnostic(path, eTree.tag, fmt.Errorf("expanding macros: %w", err)))
+		lazyOps = nil
+	} else {
+		eTree.LazyOps = lazyOps
+	}
+
+	ops := make([]ResolvedOperation, 0, len(lazyOps))
+	for i, lazyOp := range lazyOps {
+		rOp, err := reg.resolveOperation(grammar.Key(), lazyOp)
+		if err != nil {
+			d := opDiagnostic(path, eTree.tag, lazyOp, fmt.Errorf("resolving operation %s: %w", lazyOp.Name, err))
+			if errors.Is(err, ErrOpNotFound) {
+				d.Suggestion = suggestUnknownOp(grammar, reg.namesIn(grammar.Key()), lazyOps, i)
+			}
+			*diags = append(*diags, d)
+			continue
+		}
+
+		rOp.Opts, err = describedOpts(rOp.Op, rOp.Opts)
 		if err != nil {
-			return fmt.Errorf("field %s, resolving operation %s: %w", eTree.Name, lazyOp.Name, err)
+			*diags = append(*diags, opDiagnostic(path, eTree.tag, lazyOp, fmt.Errorf("operation %s: %w", lazyOp.Name, err)))
+			continue
+		}
+
+		// Factories instantiate an operation per field
+		rOp.Op, err = instantiate(rOp)
+		if err != nil {
+			*diags = append(*diags, opDiagnostic(path, eTree.tag, lazyOp, fmt.Errorf("instantiating operation %s: %w", lazyOp.Name, err)))
+			continue
 		}
 
 		if rOp.Op.Arity() != arity {
-			return fmt.Errorf("field %s, operation %s arity %d does not match recipe arity %d", eTree.Name, lazyOp.Name, rOp.Op.Arity(), arity)
+			err := fmt.Errorf("operation %s arity %d does not match recipe arity %d", lazyOp.Name, rOp.Op.Arity(), arity)
+			*diags = append(*diags, opDiagnostic(path, eTree.tag, lazyOp, err))
+			continue
 		}
 
-		eTree.Operations = append(eTree.Operations, *rOp)
+		// Apply walks source operations from values, not fields
+		if !eTree.hasChild() && grammar.WalkType() != ApplyWalk {
+			if err := checkSourceType(rOp.Op, eTree); err != nil {
+				*diags = append(*diags, opDiagnostic(path, eTree.tag, lazyOp, fmt.Errorf("operation %s: %w", lazyOp.Name, err)))
+				continue
+			}
+		}
+
+		ops = append(ops, *rOp)
 	}
+	eTree.Operations = ops
 
 	for _, child := range eTree.Children {
-		err := exec.resolveTree(child, arity)
-		if err != nil {
-			return err
-		}
+		exec.resolveTree(reg, grammar, child, arity, joinPath(path, child.Name), diags)
 	}
-
-	return nil
 }
 
 func (exec *Executor) validKind(t reflect.Type) error {
@@ -180,7 +281,21 @@ func (exec *Executor) elemType(t reflect.Type) (reflect.Type, error) {
 	return t.Elem(), nil
 }
 
+// joinPath appends a field name to a dotted field path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
 func (exec *Executor) extractChildPointers(eTree *ExecTree, wPtrs []unsafe.Pointer) []unsafe.Pointer {
+	// Leaf nodes extract their field from the parent struct pointer,
+	// struct fields with operations but no walked fields included
+	if eTree.structAddressor == nil || !eTree.hasChild() {
+		return wPtrs
+	}
+
 	cPtrs := make([]unsafe.Pointer, len(wPtrs))
 	for i, wPtr := range wPtrs {
 		cPtrs[i] = eTree.structAddressor(wPtr)
@@ -196,6 +311,20 @@ func (exec *Executor) extractFieldValues(eTree *ExecTree, wPtrs []unsafe.Pointer
 	return wFields
 }
 
+// runOp executes operation on sources, unless tracing a dry run.
+//
+// Reports whether the operation ran, and records it when tracing.
+func (exec *Executor) runOp(tr *tracer, path string, operation ResolvedOperation, sources []any) (any, bool, error) {
+	if tr.dryRun() {
+		tr.op(path, operation.Name, sources, nil, nil)
+		return nil, false, nil
+	}
+
+	res, err := operation.Op.Execute(operation.Opts, sources...) // Must unpack slice
+	tr.op(path, operation.Name, sources, res, err)
+	return res, true, err
+}
+
 //--------------------------------------------------------------------------------
 // Combine Walk
 //  Performs a combine walk over variadic arity of walked structs,
@@ -208,65 +337,133 @@ func (exec *Executor) ExecuteCombineWalk(ctx *ExecContext, walked []any) (any, e
 		return nil, fmt.Errorf("preparing combine execute: %w", err)
 	}
 
-	wPtrs := make([]unsafe.Pointer, len(walked))
-	for i, w := range walked {
-		wPtrs[i] = unsafe.Pointer(reflect.ValueOf(w).Pointer())
-	}
+	return exec.combineWalk(ctx, rcp, walked)
+}
 
-	acc, err := exec.walkCombiner(rcp.combiner, rcp.Root, wPtrs)
+// combineWalk executes the prepared combine recipe rcp on walked.
+func (exec *Executor) combineWalk(ctx *ExecContext, rcp *Recipe, walked []any) (any, error) {
+	tr := exec.newTracer(ctx, rcp)
+	tr.structNode(rcp.Root, rcp.Type, "")
+
+	// Traced walks take the reflective walk, which records every step
+	goCtx, workers, concurrent := exec.concurrency(ctx)
+	var (
+		acc any
+		err error
+	)
+	switch {
+	case tr != nil:
+		wPtrs := make([]unsafe.Pointer, len(walked))
+		for i, w := range walked {
+			wPtrs[i] = unsafe.Pointer(reflect.ValueOf(w).Pointer())
+		}
+		acc, err = exec.walkCombiner(rcp.combiner, rcp.Root, wPtrs, "", tr)
+	case concurrent:
+		acc, err = rcp.plan.runParallel(goCtx, workers, rcp.combiner, walked)
+	case rcp.generated != nil && len(walked) == 1:
+		acc, err = rcp.generated.combine(rcp.generated.fields, rcp.combiner, walked[0])
+	default:
+		acc, err = rcp.plan.run(rcp.combiner, walked)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("executing combine walk: %w", err)
 	}
 
 	acc = rcp.combiner.Combine(rcp.combiner.Zero(), acc)
+	if !tr.dryRun() {
+		tr.combine("", acc)
+	}
 	return acc, nil
 }
 
 // walkCombiner is the internal implementation of the combine walk.
 //
 // wlPtrs: slice of unsafe.Pointer to the current struct level (child of root) being walked.
-func (exec *Executor) walkCombiner(combiner Combiner, eTree *ExecTree, wPtrs []unsafe.Pointer) (any, error) {
+// path: dotted field path of eTree from the root, empty for the root.
+func (exec *Executor) walkCombiner(combiner Combiner, eTree *ExecTree, wPtrs []unsafe.Pointer, path string, tr *tracer) (any, error) {
 	acc := combiner.Zero()
+	fc, isFieldCombiner := combiner.(FieldCombiner)
+	sc, isShortCircuit := combiner.(ShortCircuitCombiner)
 
 	// Struct node
 	if eTree.hasChild() {
-		for _, cTree := range eTree.Children {
+		tr.skipOps(path, eTree.Operations, "struct operations only run in ApplyWalk")
+
+		for i, cTree := range eTree.Children {
+			cPath := joinPath(path, cTree.Name)
 			cPtrs := exec.extractChildPointers(cTree, wPtrs)
+			if cTree.hasChild() {
+				tr.structNode(cTree, cTree.fieldType, cPath)
+			}
 
-			res, err := exec.walkCombiner(combiner, cTree, cPtrs)
+			res, err := exec.walkCombiner(combiner, cTree, cPtrs, cPath, tr)
 			if err != nil {
 				return nil, fmt.Errorf("executing struct child %s: %w", cTree.Name, err)
 			}
+			if tr.dryRun() {
+				continue
+			}
+
 			acc = combiner.Combine(acc, res)
+			tr.combine(path, acc)
+
+			if isShortCircuit && sc.Done(acc) {
+				for _, sTree := range eTree.Children[i+1:] {
+					tr.skip(joinPath(path, sTree.Name), "combiner is done")
+				}
+				break
+			}
 		}
 		return acc, nil
 	}
 
 	// Leaf node
-	if eTree.hasOperation() {
-		wFields := exec.extractFieldValues(eTree, wPtrs)
+	if !eTree.hasOperation() {
+		tr.skip(path, "no resolved operations")
+		return acc, nil
+	}
 
-		for _, operation := range eTree.Operations {
-			res, err := operation.Op.Execute(operation.Opts, wFields...) // Must unpack slice
-			if err != nil {
-				// Handle opts here
-				if false /*opts placehold*/ {
+	wFields := exec.extractFieldValues(eTree, wPtrs)
 
-				} else {
-					return nil, fmt.Errorf("executing operation %s on field %s: %w", operation.Name, eTree.Name, err)
-				}
-			}
+	for i, operation := range eTree.Operations {
+		res, ran, err := exec.runOp(tr, path, operation, wFields)
+		if err != nil {
+			// Handle opts here
+			if false /*opts placehold*/ {
 
-			switch eTree.OpStrategy {
-			case FirstSuccess:
-				acc = res
-				return acc, nil
-			case AllOrNothing:
-				acc = combiner.Combine(acc, res)
-			default:
-				return nil, fmt.Errorf("unknown multi-op strategy %d", eTree.OpStrategy)
+			} else {
+				return nil, fmt.Errorf("executing operation %s on field %s: %w", operation.Name, eTree.Name, err)
 			}
 		}
+
+		switch {
+		case !ran && eTree.OpStrategy == FirstSuccess:
+			tr.skipOps(path, eTree.Operations[i+1:], "FirstSuccess stops after the first operation")
+			return acc, nil
+		case !ran:
+		case eTree.OpStrategy == FirstSuccess && isFieldCombiner:
+			acc = fc.CombineField(acc, path, res)
+			tr.combine(path, acc)
+			tr.skipOps(path, eTree.Operations[i+1:], "FirstSuccess stops after the first operation")
+			return acc, nil
+		case eTree.OpStrategy == FirstSuccess:
+			acc = res
+			tr.skipOps(path, eTree.Operations[i+1:], "FirstSuccess stops after the first operation")
+			return acc, nil
+		case eTree.OpStrategy == AllOrNothing && isFieldCombiner:
+			acc = fc.CombineField(acc, path, res)
+			tr.combine(path, acc)
+		case eTree.OpStrategy == AllOrNothing:
+			acc = combiner.Combine(acc, res)
+			tr.combine(path, acc)
+		default:
+			return nil, fmt.Errorf("unknown multi-op strategy %d", eTree.OpStrategy)
+		}
+
+		if ran && isShortCircuit && sc.Done(acc) {
+			tr.skipOps(path, eTree.Operations[i+1:], "combiner is done")
+			return acc, nil
+		}
 	}
 
 	return acc, nil
@@ -278,26 +475,54 @@ func (exec *Executor) ExecuteApplyWalk(ctx *ExecContext, walked []any, vals []an
 		return fmt.Errorf("preparing apply execute: %w", err)
 	}
 
+	return exec.applyWalk(ctx, rcp, walked, vals)
+}
+
+// applyWalk executes the prepared apply recipe rcp on walked.
+func (exec *Executor) applyWalk(ctx *ExecContext, rcp *Recipe, walked []any, vals []any) error {
 	wPtrs := make([]unsafe.Pointer, len(walked))
 	for i, w := range walked {
 		wPtrs[i] = unsafe.Pointer(reflect.ValueOf(w).Pointer())
 	}
 
-	return exec.walkApplier(rcp.applier, rcp.Root, wPtrs, vals)
+	tr := exec.newTracer(ctx, rcp)
+	tr.structNode(rcp.Root, rcp.Type, "")
+
+	if goCtx, workers, ok := exec.concurrency(ctx); ok && tr == nil {
+		return exec.walkApplierParallel(goCtx, workers, rcp.applier, rcp.Root, wPtrs, vals)
+	}
+	return exec.walkApplier(rcp.applier, rcp.Root, wPtrs, vals, "", tr)
 }
 
 // walkApplier is the internal implementation of the apply walk.
 //
 // Applies results of operations to the walked structs using the provided applier.
+// Operations returning a nil result leave their field untouched.
 //
 // wlPtrs: slice of unsafe.Pointer to the current struct level (child of root) being walked.
-func (exec *Executor) walkApplier(applier Applier, eTree *ExecTree, wPtrs []unsafe.Pointer, vals []any) error {
+// path: dotted field path of eTree from the root, empty for the root.
+func (exec *Executor) walkApplier(applier Applier, eTree *ExecTree, wPtrs []unsafe.Pointer, vals []any, path string, tr *tracer) error {
 
 	if eTree.hasChild() {
+		// Struct node operations derive the sources of its children
+		for _, operation := range eTree.Operations {
+			res, _, err := exec.runOp(tr, path, operation, vals)
+			if err != nil {
+				return fmt.Errorf("executing operation %s on struct %s: %w", operation.Name, eTree.Name, err)
+			}
+			if res != nil {
+				vals = []any{res}
+			}
+		}
+
 		for _, cTree := range eTree.Children {
+			cPath := joinPath(path, cTree.Name)
 			cPtrs := exec.extractChildPointers(cTree, wPtrs)
+			if cTree.hasChild() {
+				tr.structNode(cTree, cTree.fieldType, cPath)
+			}
 
-			err := exec.walkApplier(applier, cTree, cPtrs, vals)
+			err := exec.walkApplier(applier, cTree, cPtrs, vals, cPath, tr)
 			if err != nil {
 				return fmt.Errorf("executing struct child %s: %w", cTree.Name, err)
 			}
@@ -305,48 +530,256 @@ func (exec *Executor) walkApplier(applier Applier, eTree *ExecTree, wPtrs []unsa
 		return nil
 	}
 
-	if eTree.hasOperation() {
-		for _, operation := range eTree.Operations {
-			res, err := operation.Op.Execute(operation.Opts, vals...)
+	if !eTree.hasOperation() {
+		tr.skip(path, "no resolved operations")
+		return nil
+	}
+
+	for i, operation := range eTree.Operations {
+		res, ran, err := exec.runOp(tr, path, operation, vals)
+		if err != nil {
+			// Handle opts here
+			if false /*opts placehold*/ {
+
+			} else {
+				return fmt.Errorf("executing operation %s: %w", operation.Name, err)
+			}
+		}
+
+		if ran {
+			err = exec.applyResult(applier, eTree, wPtrs, res, path, tr)
 			if err != nil {
-				// Handle opts here
-				if false /*opts placehold*/ {
+				return err
+			}
+		}
 
-				} else {
-					return fmt.Errorf("executing operation %s: %w", operation.Name, err)
-				}
+		switch eTree.OpStrategy {
+		case FirstSuccess:
+			tr.skipOps(path, eTree.Operations[i+1:], "FirstSuccess stops after the first operation")
+			return nil
+		case AllOrNothing:
+			continue
+		default:
+			return fmt.Errorf("unknown multi-op strategy %d", eTree.OpStrategy)
+		}
+	}
+
+	return nil
+}
+
+// applyResult applies res to the field of eTree in each of wPtrs.
+//
+// Nil results leave the field untouched.
+func (exec *Executor) applyResult(applier Applier, eTree *ExecTree, wPtrs []unsafe.Pointer, res any, path string, tr *tracer) error {
+	if res == nil {
+		tr.skip(path, "nil result leaves the field untouched")
+		return nil
+	}
+
+	for _, wPtr := range wPtrs {
+		err := applier.Apply(wPtr, eTree.fieldOffset, eTree.fieldType, res)
+		if err != nil {
+			return fmt.Errorf("applying result to field %s: %w", eTree.Name, err)
+		}
+	}
+
+	tr.apply(path, res)
+	return nil
+}
+
+//--------------------------------------------------------------------------------
+// Apply Fields Walk
+//  Performs an apply walk whose sources are structs of the walked type,
+//  walked alongside the walked structs.
+//--------------------------------------------------------------------------------
+
+// ExecuteApplyFieldsWalk applies operation results to the walked structs,
+// like [Executor.ExecuteApplyWalk], but with sources of the walked type.
+//
+// Operations receive the current value of their field in each walked
+// struct, followed by its value in each source. Operations on a struct
+// field apply to the struct as a whole instead of walking its children.
+func (exec *Executor) ExecuteApplyFieldsWalk(ctx *ExecContext, walked []any, sources []any) error {
+	all := make([]any, 0, len(walked)+len(sources))
+	all = append(append(all, walked...), sources...)
+
+	rcp, err := exec.prepareExecute(ctx, ApplyWalk, all)
+	if err != nil {
+		return fmt.Errorf("preparing apply fields execute: %w", err)
+	}
+
+	aPtrs := make([]unsafe.Pointer, len(all))
+	for i, a := range all {
+		aPtrs[i] = unsafe.Pointer(reflect.ValueOf(a).Pointer())
+	}
+
+	tr := exec.newTracer(ctx, rcp)
+	tr.structNode(rcp.Root, rcp.Type, "")
+
+	return exec.walkFieldsApplier(rcp.applier, rcp.Root, aPtrs, len(walked), "", tr)
+}
+
+// walkFieldsApplier is the internal implementation of the apply fields walk.
+//
+// aPtrs: walked struct pointers followed by source struct pointers, at the
+// current struct level. Results are applied to the first nWalked only.
+// path: dotted field path of eTree from the root, empty for the root.
+func (exec *Executor) walkFieldsApplier(applier Applier, eTree *ExecTree, aPtrs []unsafe.Pointer, nWalked int, path string, tr *tracer) error {
+
+	if eTree.hasChild() && !eTree.hasOperation() {
+		for _, cTree := range eTree.Children {
+			cPath := joinPath(path, cTree.Name)
+			cPtrs := exec.extractChildPointers(cTree, aPtrs)
+			if cTree.hasChild() && !cTree.hasOperation() {
+				tr.structNode(cTree, cTree.fieldType, cPath)
 			}
 
-			for _, wPtr := range wPtrs {
-				err := applier.Apply(wPtr, eTree.fieldOffset, eTree.fieldType, res)
-				if err != nil {
-					return fmt.Errorf("applying result to field %s: %w", eTree.Name, err)
-				}
+			err := exec.walkFieldsApplier(applier, cTree, cPtrs, nWalked, cPath, tr)
+			if err != nil {
+				return fmt.Errorf("executing struct child %s: %w", cTree.Name, err)
 			}
+		}
+		return nil
+	}
 
-			switch eTree.OpStrategy {
-			case FirstSuccess:
-				return nil
-			case AllOrNothing:
-				continue
-			default:
-				return fmt.Errorf("unknown multi-op strategy %d", eTree.OpStrategy)
+	if !eTree.hasOperation() {
+		tr.skip(path, "no resolved operations")
+		return nil
+	}
+
+	for i, operation := range eTree.Operations {
+		aFields := exec.extractFieldValues(eTree, aPtrs)
+
+		res, ran, err := exec.runOp(tr, path, operation, aFields)
+		if err != nil {
+			return fmt.Errorf("executing operation %s on field %s: %w", operation.Name, eTree.Name, err)
+		}
+
+		if ran {
+			err = exec.applyResult(applier, eTree, aPtrs[:nWalked], res, path, tr)
+			if err != nil {
+				return err
 			}
 		}
+
+		switch eTree.OpStrategy {
+		case FirstSuccess:
+			tr.skipOps(path, eTree.Operations[i+1:], "FirstSuccess stops after the first operation")
+			return nil
+		case AllOrNothing:
+			continue
+		default:
+			return fmt.Errorf("unknown multi-op strategy %d", eTree.OpStrategy)
+		}
+	}
+
+	return nil
+}
+
+//--------------------------------------------------------------------------------
+// Transform Walk
+//  Performs a transform walk over the walked structs, writing the result
+//  of each operation back into the field it was computed from.
+//--------------------------------------------------------------------------------
+
+func (exec *Executor) ExecuteTransformWalk(ctx *ExecContext, walked []any) error {
+	rcp, err := exec.prepareExecute(ctx, TransformWalk, walked)
+	if err != nil {
+		return fmt.Errorf("preparing transform execute: %w", err)
+	}
+
+	return exec.transformWalk(ctx, rcp, walked)
+}
+
+// transformWalk executes the prepared transform recipe rcp on walked.
+func (exec *Executor) transformWalk(ctx *ExecContext, rcp *Recipe, walked []any) error {
+	wPtrs := make([]unsafe.Pointer, len(walked))
+	for i, w := range walked {
+		wPtrs[i] = unsafe.Pointer(reflect.ValueOf(w).Pointer())
+	}
+
+	tr := exec.newTracer(ctx, rcp)
+	tr.structNode(rcp.Root, rcp.Type, "")
+
+	err := exec.walkTransformer(rcp.Root, wPtrs, "", tr)
+	if err != nil {
+		return fmt.Errorf("executing transform walk: %w", err)
+	}
+
+	if rcp.transformer == nil || tr.dryRun() {
+		return nil
+	}
+
+	for i, w := range walked {
+		err := rcp.transformer.Transform(w)
+		if err != nil {
+			return fmt.Errorf("transforming walked argument %d: %w", i, err)
+		}
 	}
 
 	return nil
 }
 
-// type TransformExecutor struct {
-// 	executor
-// }
+// walkTransformer is the internal implementation of the transform walk.
+//
+// Field values are re-extracted after every operation, so multiple
+// operations on a field see the result of the previous one.
+//
+// wlPtrs: slice of unsafe.Pointer to the current struct level (child of root) being walked.
+// path: dotted field path of eTree from the root, empty for the root.
+func (exec *Executor) walkTransformer(eTree *ExecTree, wPtrs []unsafe.Pointer, path string, tr *tracer) error {
+
+	if eTree.hasChild() {
+		tr.skipOps(path, eTree.Operations, "struct operations only run in ApplyWalk")
+
+		for _, cTree := range eTree.Children {
+			cPath := joinPath(path, cTree.Name)
+			cPtrs := exec.extractChildPointers(cTree, wPtrs)
+			if cTree.hasChild() {
+				tr.structNode(cTree, cTree.fieldType, cPath)
+			}
+
+			err := exec.walkTransformer(cTree, cPtrs, cPath, tr)
+			if err != nil {
+				return fmt.Errorf("executing struct child %s: %w", cTree.Name, err)
+			}
+		}
+		return nil
+	}
+
+	if !eTree.hasOperation() {
+		tr.skip(path, "no resolved operations")
+		return nil
+	}
 
-// func (exec *TransformExecutor) ExecuteTransformWalk(ctx *ExecContext, walked []any) error {
-// 	_, err := exec.prepareExecute(ctx, TransformWalk, walked)
-// 	if err != nil {
-// 		return fmt.Errorf("preparing transform execute: %w", err)
-// 	}
+	for i, operation := range eTree.Operations {
+		wFields := exec.extractFieldValues(eTree, wPtrs)
+
+		res, ran, err := exec.runOp(tr, path, operation, wFields)
+		if err != nil {
+			return fmt.Errorf("executing operation %s on field %s: %w", operation.Name, eTree.Name, err)
+		}
 
-// 	return nil
-// }
+		if ran {
+			for _, wPtr := range wPtrs {
+				err := setField(wPtr, eTree.fieldOffset, eTree.fieldType, res)
+				if err != nil {
+					return fmt.Errorf("transforming field %s: %w", eTree.Name, err)
+				}
+			}
+			tr.apply(path, res)
+		}
+
+		switch eTree.OpStrategy {
+		case FirstSuccess:
+			tr.skipOps(path, eTree.Operations[i+1:], "FirstSuccess stops after the first operation")
+			return nil
+		case AllOrNothing:
+			continue
+		default:
+			return fmt.Errorf("unknown multi-op strategy %d", eTree.OpStrategy)
+		}
+	}
+
+	return nil
+}
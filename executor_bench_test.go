@@ -0,0 +1,103 @@
+package recipe
+
+import (
+	"testing"
+)
+
+const benchGrammarKey = "bench"
+
+type benchScalars struct {
+	Name    string  `bench:"nonempty"`
+	Email   string  `bench:"nonempty"`
+	Age     int     `bench:"positive"`
+	Score   float64 `bench:"positivef"`
+	Active  bool    `bench:"set"`
+	Ignored string  `bench:"-"`
+}
+
+type benchNested struct {
+	ID      int `bench:"positive"`
+	Profile struct {
+		Name  string `bench:"nonempty"`
+		Level int    `bench:"positive"`
+	}
+}
+
+func newBenchExecutor(tb testing.TB) *Executor {
+	tb.Helper()
+
+	grammar, err := NewGrammarConfig().
+		SetKey(benchGrammarKey).
+		SetWalkType(CombineWalk).
+		SetCombiner(BoolAndCombiner{}).
+		SetOpArity(OpUnary).
+		SetArity(GrammarArityVariadic).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	reg := NewOpRegistry()
+	reg.RegisterOperation("nonempty", Unary(func(_ OpOpts, s string) (bool, error) { return s != "", nil }))
+	reg.RegisterOperation("set", Unary(func(_ OpOpts, b bool) (bool, error) { return b, nil }))
+	reg.RegisterOperation("positive", Unary(func(_ OpOpts, i int) (bool, error) { return i > 0, nil }))
+	reg.RegisterOperation("positivef", Unary(func(_ OpOpts, f float64) (bool, error) { return f > 0, nil }))
+
+	return NewExecutor(reg, NewBuilder(grammar))
+}
+
+func TestCombineWalkAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not counted reliably under the race detector")
+	}
+
+	exec := newBenchExecutor(t)
+	walked := []any{&benchScalars{Name: "Ada", Email: "ada@example.com", Age: 36, Score: 9.5, Active: true}}
+
+	res, err := exec.ExecuteCombineWalk(nil, walked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != true {
+		t.Fatalf("combined %v, want true", res)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = exec.ExecuteCombineWalk(nil, walked)
+	})
+	if allocs != 0 {
+		t.Errorf("combine walk allocates %v times, want 0", allocs)
+	}
+}
+
+func BenchmarkCombineWalkScalars(b *testing.B) {
+	exec := newBenchExecutor(b)
+	walked := []any{&benchScalars{Name: "Ada", Email: "ada@example.com", Age: 36, Score: 9.5, Active: true}}
+
+	if _, err := exec.ExecuteCombineWalk(nil, walked); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _ = exec.ExecuteCombineWalk(nil, walked)
+	}
+}
+
+func BenchmarkCombineWalkNested(b *testing.B) {
+	exec := newBenchExecutor(b)
+	w := &benchNested{ID: 7}
+	w.Profile.Name, w.Profile.Level = "Ada", 3
+	walked := []any{w}
+
+	if _, err := exec.ExecuteCombineWalk(nil, walked); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _ = exec.ExecuteCombineWalk(nil, walked)
+	}
+}
@@ -0,0 +1,191 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// account is tagged for every built-in grammar, and for the "def"
+// grammar of newDefineExecutor, used as default.
+type account struct {
+	Name  string   `def:"echo" mask:"redact" env:"name=NAME"`
+	Email string   `mask:"email" env:"name=EMAIL"`
+	Tags  []string `merge:"append" diff:"-"`
+}
+
+// newGrammarsExecutor returns a newDefineExecutor with grammars added and
+// every built-in operation registered.
+func newGrammarsExecutor(t *testing.T, grammars ...Grammar) *Executor {
+	t.Helper()
+
+	exec := newDefineExecutor(t)
+	for _, g := range grammars {
+		if err := exec.builder.AddGrammar(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reg := exec.reg.Load()
+	if err := RegisterMaskOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterEnvOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterDiffOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterMergeOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	return exec
+}
+
+func TestGrammarSelection(t *testing.T) {
+	maskT, err := NewMaskGrammar(TransformWalk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maskC, err := NewMaskGrammar(CombineWalk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := NewEnvGrammar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := NewDiffGrammar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	merge, err := NewMergeGrammar()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acc := account{Name: "Ada", Email: "ada@example.com", Tags: []string{"a"}}
+
+	t.Run("redact", func(t *testing.T) {
+		exec := newGrammarsExecutor(t, maskT)
+		if _, err := Redact(exec, &acc); !errors.Is(err, ErrWalkTypeMismatch) {
+			t.Fatalf("default grammar: got %v, want %v", err, ErrWalkTypeMismatch)
+		}
+
+		got, err := RedactWith(exec, &ExecContext{Grammar: MaskGrammarKey}, &acc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != "[REDACTED]" || got.Email != "***@example.com" {
+			t.Errorf("redacted %+v", got)
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		exec := newGrammarsExecutor(t, env)
+		var got account
+		err := BindEnvWith(exec, &ExecContext{Grammar: EnvGrammarKey}, &got, MapEnv{"NAME": "Ada", "EMAIL": "ada@example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != "Ada" || got.Email != "ada@example.com" {
+			t.Errorf("bound %+v", got)
+		}
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		exec := newGrammarsExecutor(t, diff)
+		changed := acc
+		changed.Email = "ada@example.org"
+
+		got, err := DiffWith(exec, &ExecContext{Grammar: DiffGrammarKey}, &acc, &changed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []FieldChange{{Path: "Email", Old: acc.Email, New: changed.Email}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		exec := newGrammarsExecutor(t, merge)
+		dst := account{Tags: []string{"a"}}
+
+		err := MergeWith(exec, &ExecContext{Grammar: MergeGrammarKey}, &dst, &account{Name: "Ada", Tags: []string{"b"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := account{Name: "Ada", Tags: []string{"a", "b"}}
+		if !reflect.DeepEqual(dst, want) {
+			t.Errorf("got %+v, want %+v", dst, want)
+		}
+	})
+
+	t.Run("log valuer", func(t *testing.T) {
+		exec := newGrammarsExecutor(t, maskC)
+		got := logJSON(t, NewLogValuerWith(exec, MaskGrammarKey, acc))
+		if got["Name"] != "[REDACTED]" || got["Email"] != "***@example.com" {
+			t.Errorf("logged %v", got)
+		}
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		exec := newGrammarsExecutor(t, maskC)
+		if _, err := exec.SchemaWith(MaskGrammarKey, reflect.TypeFor[account](), SchemaDraft202012); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := exec.SchemaWith("nope", reflect.TypeFor[account](), SchemaDraft202012); !errors.Is(err, ErrGrammarNotFound) {
+			t.Errorf("got %v, want %v", err, ErrGrammarNotFound)
+		}
+	})
+
+	t.Run("warm", func(t *testing.T) {
+		exec := newGrammarsExecutor(t, maskC)
+		if err := exec.WarmWith(MaskGrammarKey, reflect.TypeFor[*account]()); err != nil {
+			t.Fatal(err)
+		}
+		if err := exec.WarmWith("nope", reflect.TypeFor[account]()); !errors.Is(err, ErrGrammarNotFound) {
+			t.Errorf("got %v, want %v", err, ErrGrammarNotFound)
+		}
+	})
+}
+
+func TestContextOverride(t *testing.T) {
+	exec := newDefineExecutor(t)
+	ctx := &ExecContext{CombinerOverride: StringJoinCombiner{Sep: ","}}
+	v := account{Name: "Ada"}
+	want := map[string]any{"Name": "Ada"}
+
+	got, err := exec.ExecuteCombineWalk(ctx, []any{&v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Ada" {
+		t.Errorf("overridden combine: got %v, want Ada", got)
+	}
+
+	// Overrides apply to their call only
+	got, err = exec.ExecuteCombineWalk(nil, []any{&v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("after an override: got %v, want %v", got, want)
+	}
+
+	br, err := exec.ExecuteBatch(ctx, []account{v}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(br.Results, []any{"Ada"}) {
+		t.Errorf("overridden batch: got %v", br.Results)
+	}
+	br, err = exec.ExecuteBatch(nil, []account{v}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(br.Results, []any{want}) {
+		t.Errorf("after an overridden batch: got %v, want %v", br.Results, []any{want})
+	}
+}
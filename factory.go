@@ -0,0 +1,63 @@
+package recipe
+
+import "fmt"
+
+// OperationFactory is an optional interface for a registered [Operation]
+// that instantiates an operation per use in a recipe.
+//
+// New is called once for each operation of each field, when the recipe
+// is resolved, with the options parsed from the tag. The walks invoke
+// the returned operation, which can hold precompiled state, e.g. a
+// regular expression compiled from a `pattern=` modifier. An error
+// fails the resolution of the recipe, reported as a [Diagnostic].
+//
+// The factory itself is only used for its registration metadata, e.g.
+// [KindedOperation] and [DescribedOperation].
+type OperationFactory interface {
+	Operation
+
+	New(opts OpOpts) (Operation, error)
+}
+
+// FactoryFunc returns an [OperationFactory] of operations of arity built
+// by fn. Executed directly, it builds an operation for every call.
+func FactoryFunc(arity OpArity, fn func(opts OpOpts) (Operation, error)) OperationFactory {
+	return factoryFunc{arity: arity, fn: fn}
+}
+
+type factoryFunc struct {
+	arity OpArity
+	fn    func(opts OpOpts) (Operation, error)
+}
+
+var __ctc__factoryFunc_impl_OperationFactory OperationFactory = factoryFunc{}
+
+func (f factoryFunc) Arity() OpArity { return f.arity }
+func (f factoryFunc) New(opts OpOpts) (Operation, error) {
+	return f.fn(opts)
+}
+func (f factoryFunc) Execute(opts OpOpts, sources ...any) (any, error) {
+	op, err := f.fn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return op.Execute(opts, sources...)
+}
+
+// instantiate returns the operation of rOp to execute: a new one if
+// registered as an [OperationFactory], the registered one otherwise.
+func instantiate(rOp *ResolvedOperation) (Operation, error) {
+	factory, ok := rOp.Op.(OperationFactory)
+	if !ok {
+		return rOp.Op, nil
+	}
+
+	op, err := factory.New(rOp.Opts)
+	if err != nil {
+		return nil, err
+	}
+	if op == nil {
+		return nil, fmt.Errorf("%w: factory returned no operation", ErrOpInvalid)
+	}
+	return op, nil
+}
@@ -0,0 +1,102 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"sync/atomic"
+	"testing"
+)
+
+type matched struct {
+	A string `def:"match=^a"`
+	B string `def:"match=b$"`
+}
+
+type badlyMatched struct {
+	A string `def:"match=("`
+}
+
+type nilMatched struct {
+	A string `def:"match"`
+}
+
+// newMatchFactory returns a factory of operations matching a `match=`
+// pattern, counting the operations it builds.
+func newMatchFactory(built *atomic.Int64) OperationFactory {
+	return FactoryFunc(OpUnary, func(opts OpOpts) (Operation, error) {
+		pattern, _ := opts.Modifier("match")
+		if pattern == "" {
+			return nil, nil
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		built.Add(1)
+		return Unary(func(_ OpOpts, s string) (bool, error) { return re.MatchString(s), nil }), nil
+	})
+}
+
+func TestOperationFactory(t *testing.T) {
+	var built atomic.Int64
+	exec := newDefineExecutor(t)
+	if err := exec.reg.Load().RegisterOperation("match", newMatchFactory(&built)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{"A": true, "B": false}
+	for range 3 {
+		got, err := exec.ExecuteCombineWalk(nil, []any{&matched{A: "abc", B: "abc"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+
+	// Once per field, when the recipe is resolved
+	if n := built.Load(); n != 2 {
+		t.Errorf("built %d operations, want 2", n)
+	}
+}
+
+func TestOperationFactoryErrors(t *testing.T) {
+	var built atomic.Int64
+	exec := newDefineExecutor(t)
+	if err := exec.reg.Load().RegisterOperation("match", newMatchFactory(&built)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := exec.ExecuteCombineWalk(nil, []any{&badlyMatched{}})
+	var diags BuildDiagnostics
+	if !errors.As(err, &diags) || len(diags) != 1 || diags[0].Path != "A" {
+		t.Errorf("got %v, want a diagnostic of A", err)
+	}
+
+	_, err = exec.ExecuteCombineWalk(nil, []any{&nilMatched{}})
+	if !errors.Is(err, ErrOpInvalid) {
+		t.Errorf("got %v, want %v", err, ErrOpInvalid)
+	}
+}
+
+func TestFactoryFuncExecute(t *testing.T) {
+	var built atomic.Int64
+	factory := newMatchFactory(&built)
+
+	for range 2 {
+		got, err := factory.Execute(Modifiers{"match": "^a"}, "abc")
+		if err != nil || got != true {
+			t.Errorf("got %v, %v", got, err)
+		}
+	}
+	if n := built.Load(); n != 2 {
+		t.Errorf("built %d operations, want 2", n)
+	}
+
+	if _, err := factory.Execute(Modifiers{"match": "("}, "abc"); err == nil {
+		t.Error("executed an invalid pattern")
+	}
+}
@@ -0,0 +1,158 @@
+package recipe
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// TypedOperation is an optional interface for an [Operation] callable
+// with a source of type T without boxing it, used by generated walkers.
+type TypedOperation[T any] interface {
+	Operation
+
+	ExecuteTyped(opts OpOpts, source T) (any, error)
+}
+
+// GeneratedRecipe is a reflection-free combine walk of one struct type
+// for one grammar, emitted by cmd/recipegen.
+//
+// The [Executor] prefers it to the reflective walk, unless the recipe
+// it was generated from no longer matches the built one.
+type GeneratedRecipe struct {
+	Grammar string
+	Type    reflect.Type
+
+	// Fields are the walked leaves, in walk order, with the names of
+	// their operations at generation time.
+	Fields []GeneratedField
+
+	// Combine walks walked, a pointer to Type, with the resolved
+	// fields in the order of Fields.
+	Combine func(fields []BoundField, combiner Combiner, walked any) (any, error)
+}
+
+// GeneratedField is a leaf of a [GeneratedRecipe].
+type GeneratedField struct {
+	Path string
+	Ops  []string
+}
+
+// BoundField is a leaf of a [GeneratedRecipe], bound to the operations
+// of the resolved recipe.
+type BoundField struct {
+	Path       string
+	Operations []ResolvedOperation
+	OpStrategy MultiOpStrategy
+}
+
+var generated = struct {
+	mu      sync.RWMutex
+	recipes map[recipeKey]GeneratedRecipe
+}{recipes: map[recipeKey]GeneratedRecipe{}}
+
+// RegisterGenerated registers a generated recipe, from the init function
+// of generated code.
+//
+// Only recipes resolved after registration use it.
+func RegisterGenerated(gr GeneratedRecipe) {
+	generated.mu.Lock()
+	generated.recipes[recipeKey{gr.Grammar, gr.Type}] = gr
+	generated.mu.Unlock()
+}
+
+// generatedPlan is a generated recipe bound to a resolved recipe.
+type generatedPlan struct {
+	combine func(fields []BoundField, combiner Combiner, walked any) (any, error)
+	fields  []BoundField
+}
+
+// bindGenerated binds the generated recipe of rcp, if any and still
+// matching its leaves. Returns nil otherwise.
+func bindGenerated(rcp *Recipe) *generatedPlan {
+	if rcp.WalkType != CombineWalk {
+		return nil
+	}
+
+	generated.mu.RLock()
+	gr, ok := generated.recipes[recipeKey{rcp.Grammar, rcp.Type}]
+	generated.mu.RUnlock()
+
+	if !ok || gr.Combine == nil {
+		return nil
+	}
+
+	var fields []BoundField
+	for _, cTree := range rcp.Root.Children {
+		collectLeaves(cTree, cTree.Name, &fields)
+	}
+
+	if len(fields) != len(gr.Fields) {
+		return nil
+	}
+	for i, field := range fields {
+		names := make([]string, len(field.Operations))
+		for j, operation := range field.Operations {
+			names[j] = operation.Name
+		}
+		if field.Path != gr.Fields[i].Path || !slices.Equal(names, gr.Fields[i].Ops) {
+			return nil
+		}
+	}
+
+	return &generatedPlan{combine: gr.Combine, fields: fields}
+}
+
+// collectLeaves appends the leaves of eTree, at path, in walk order.
+func collectLeaves(eTree *ExecTree, path string, fields *[]BoundField) {
+	if !eTree.hasChild() {
+		*fields = append(*fields, BoundField{Path: path, Operations: eTree.Operations, OpStrategy: eTree.OpStrategy})
+		return
+	}
+
+	for _, cTree := range eTree.Children {
+		collectLeaves(cTree, joinPath(path, cTree.Name), fields)
+	}
+}
+
+// CombineLeaf runs the operations of a leaf on source and combines their
+// results, as the reflective combine walk does. Called by generated code.
+func CombineLeaf[T any](combiner Combiner, field *BoundField, source T) (any, error) {
+	acc := combiner.Zero()
+	fc, isFieldCombiner := combiner.(FieldCombiner)
+
+	for _, operation := range field.Operations {
+		var (
+			res any
+			err error
+		)
+		if typed, ok := operation.Op.(TypedOperation[T]); ok {
+			res, err = typed.ExecuteTyped(operation.Opts, source)
+		} else {
+			res, err = operation.Op.Execute(operation.Opts, source)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("executing operation %s on field %s: %w", operation.Name, field.Path, err)
+		}
+
+		switch {
+		case field.OpStrategy == FirstSuccess && isFieldCombiner:
+			return fc.CombineField(acc, field.Path, res), nil
+		case field.OpStrategy == FirstSuccess:
+			return res, nil
+		case field.OpStrategy == AllOrNothing && isFieldCombiner:
+			acc = fc.CombineField(acc, field.Path, res)
+		case field.OpStrategy == AllOrNothing:
+			acc = combiner.Combine(acc, res)
+		default:
+			return nil, fmt.Errorf("unknown multi-op strategy %d", field.OpStrategy)
+		}
+
+		if CombineDone(combiner, acc) {
+			return acc, nil
+		}
+	}
+
+	return acc, nil
+}
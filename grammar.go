@@ -3,6 +3,15 @@ package recipe
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrTagMalformed = fmt.Errorf("malformed tag")
+	ErrModInvalid   = fmt.Errorf("invalid modifier")
 )
 
 type Grammar interface {
@@ -11,6 +20,11 @@ type Grammar interface {
 
 	// Every recipe from this grammar uses the same walk type
 	WalkType() WalkType
+	// Every recipe from this grammar resolves operations of the
+	// same arity.
+	//
+	// See: [OpArity]
+	OpArity() OpArity
 	// Combiner combines multiple operation results into final
 	// output
 	//
@@ -44,6 +58,9 @@ type Grammar interface {
 	//
 	// Return an error if the tag does not conform to the grammar's
 	// structure and format.
+	//
+	// Untagged fields are split as an empty tag. Return ErrEmptyTag
+	// to leave them out of the recipe.
 	Split(tag string) ([]string, error)
 
 	// Parse parses an operation string into a LazyOperation
@@ -73,6 +90,7 @@ type baseGrammarData struct {
 	description string
 
 	walkType    WalkType
+	opArity     OpArity
 	combiner    Combiner
 	applier     Applier
 	transformer Transformer
@@ -90,6 +108,10 @@ func (bgd *baseGrammarData) WalkType() WalkType {
 	return bgd.walkType
 }
 
+func (bgd *baseGrammarData) OpArity() OpArity {
+	return bgd.opArity
+}
+
 func (bgd *baseGrammarData) Combiner() (Combiner, error) {
 	if bgd.walkType == CombineWalk {
 		return bgd.combiner, nil
@@ -114,23 +136,401 @@ func (bgd *baseGrammarData) Transformer() (Transformer, error) {
 type FlatGrammar struct {
 	baseGrammarData
 
-	TagPattern       *regexp.Regexp
+	// TagPattern captures each enclosed operation string of a tag.
+	//
+	// Only used by [FlatFormatEnclosed] grammars.
+	TagPattern *regexp.Regexp
+	// OperationPattern matches a single `<key>` or `<key>=<value>` token
+	// of an operation string.
+	//
+	// Group 1: key
+	// Group 2: quoted value (double quotes) - content without quotes
+	// Group 3: quoted value (single quotes) - content without quotes
+	// Group 4: unquoted value
 	OperationPattern *regexp.Regexp
+
+	format     FlatGrammarFormat
+	separator  FlatGrammarSeparator
+	arity      GrammarArity
+	modformat  ModifierFormat
+	defaultOp  string
+	implicitOp string
+	sharedMods map[string]ModifierSpec
+	opSpecs    map[string]OperationSpec
 }
 
 func (fg FlatGrammar) Split(tag string) ([]string, error) {
-	// $$$TODO $$$SIMON: Implement flat grammar splitting according to format and separator.
-	return nil, nil
+	opStrs, _, err := fg.splitOffsets(tag)
+	return opStrs, err
+}
+
+// splitOffsets is Split, also returning the byte offset in tag of each
+// operation string, -1 for the implicit operation.
+//
+// Operation strings are substrings of tag, so that the offsets of the
+// tokens Parse reports are relative to them.
+func (fg FlatGrammar) splitOffsets(tag string) ([]string, []int, error) {
+	if strings.TrimSpace(tag) == "" {
+		if fg.implicitOp == "" {
+			return nil, nil, ErrEmptyTag
+		}
+		return []string{fg.implicitOp}, []int{-1}, nil
+	}
+
+	var (
+		opStrs []string
+		opOffs []int
+	)
+
+	switch fg.format {
+	case FlatFormatEnclosed:
+		matches := fg.TagPattern.FindAllStringSubmatchIndex(tag, -1)
+		last := 0
+		for _, m := range matches {
+			if gap, off := trimOffset(tag, last, m[0]); gap != "" && gap != string(InlineSepComma) {
+				return nil, nil, &TokenError{Token: gap, Offset: off, Hint: fmt.Sprintf("enclose operations in %s", fg.separator), Err: fmt.Errorf("%w: unexpected text outside of %s", ErrTagMalformed, fg.separator)}
+			}
+			opStr, off := trimOffset(tag, m[2], m[3])
+			opStrs = append(opStrs, opStr)
+			opOffs = append(opOffs, off)
+			last = m[1]
+		}
+		if rest, off := trimOffset(tag, last, len(tag)); rest != "" {
+			return nil, nil, &TokenError{Token: rest, Offset: off, Hint: fmt.Sprintf("enclose operations in %s", fg.separator), Err: fmt.Errorf("%w: unexpected text outside of %s", ErrTagMalformed, fg.separator)}
+		}
+	case FlatFormatDelimited:
+		tokens, offs, err := splitTokenOffsets(tag, fg.separator[0])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// A token whose key is a known modifier of the current operation
+		// belongs to it, every other token starts a new operation.
+		// Operations span from their first token to the end of their last.
+		start, end, opkey := -1, 0, ""
+		flush := func() {
+			if start >= 0 {
+				opStrs = append(opStrs, tag[start:end])
+				opOffs = append(opOffs, start)
+			}
+		}
+		for i, tok := range tokens {
+			key := tokenKey(tok)
+			if start < 0 && fg.isModifier(fg.defaultOp, key) {
+				if fg.defaultOp == "" {
+					return nil, nil, &TokenError{Token: tok, Offset: offs[i], Err: fmt.Errorf("%w: modifier %q before any operation", ErrTagMalformed, key)}
+				}
+				// Parse completes the operation with the default
+				start, opkey = offs[i], fg.defaultOp
+			}
+			if start >= 0 && fg.isModifier(opkey, key) {
+				end = offs[i] + len(tok)
+				continue
+			}
+			flush()
+			start, end, opkey = offs[i], offs[i]+len(tok), key
+		}
+		flush()
+	default:
+		return nil, nil, fmt.Errorf("unknown flat grammar format %d", fg.format)
+	}
+
+	if len(opStrs) == 0 {
+		return nil, nil, ErrEmptyTag
+	}
+
+	if fg.arity == GrammarArityUnary && len(opStrs) > 1 {
+		err := fmt.Errorf("%w: %s grammar allows one operation, got %d", ErrTagMalformed, fg.arity, len(opStrs))
+		return nil, nil, &TokenError{Token: opStrs[1], Offset: opOffs[1], Hint: "remove the extra operations", Err: err}
+	}
+
+	return opStrs, opOffs, nil
 }
 
 func (fg FlatGrammar) Parse(opstr string) (LazyOperation, error) {
-	// $$$TODO $$$SIMON: Implement flat grammar operation parsing according to operation pattern.
-	return LazyOperation{}, nil
+	sep := byte(',')
+	if fg.format == FlatFormatDelimited {
+		sep = fg.separator[0]
+	}
+
+	tokens, offs, err := splitTokenOffsets(opstr, sep)
+	if err != nil {
+		return LazyOperation{}, err
+	}
+	if len(tokens) == 0 {
+		return LazyOperation{}, fmt.Errorf("%w: empty operation", ErrTagMalformed)
+	}
+
+	if fg.defaultOp != "" && fg.isModifier(fg.defaultOp, tokenKey(tokens[0])) {
+		tokens = append([]string{fg.defaultOp}, tokens...)
+		offs = append([]int{-1}, offs...)
+	}
+
+	if name, args, ok := cutCall(tokens[0]); ok {
+		return fg.parseCall(name, args, tokens[1:], sep)
+	}
+
+	opkey, opval, err := fg.parseToken(tokens[0])
+	if err != nil {
+		return LazyOperation{}, shiftTokenError(err, offs[0])
+	}
+
+	name := opkey
+
+	// The value of an operation is its modifier of the same key,
+	// e.g. `min=8` is min with Modifiers{"min": "8"}
+	mods := Modifiers{}
+	if strings.ContainsRune(tokens[0], '=') {
+		mods[opkey] = opval
+	}
+	for i, tok := range tokens[1:] {
+		off := offs[i+1]
+		key, val, err := fg.parseToken(tok)
+		if err != nil {
+			return LazyOperation{}, fmt.Errorf("operation %s: %w", name, shiftTokenError(err, off))
+		}
+
+		if err := fg.validateModifier(opkey, key, val, strings.ContainsRune(tok, '=')); err != nil {
+			return LazyOperation{}, fmt.Errorf("operation %s: %w", name, &TokenError{Token: tok, Offset: off, Hint: fg.modifierHint(opkey, key), Err: err})
+		}
+
+		mods[key] = val
+	}
+
+	if opSpec, ok := fg.opSpecs[opkey]; ok {
+		if err := completeModifiers(opSpec.modSpecs, mods); err != nil {
+			return LazyOperation{}, fmt.Errorf("operation %s: %w", name, &TokenError{Token: tokens[0], Offset: offs[0], Err: err})
+		}
+	}
+
+	return LazyOperation{
+		Name: name,
+		Opts: mods,
+	}, nil
+}
+
+// parseCall parses a macro call, `<name>(<args>)` followed by mods, into
+// an operation of the macro name with its arguments as modifiers.
+//
+// See: [OpRegistry.RegisterMacro]
+func (fg FlatGrammar) parseCall(name, args string, mods []string, sep byte) (LazyOperation, error) {
+	argToks, err := splitTokens(args, sep)
+	if err != nil {
+		return LazyOperation{}, fmt.Errorf("macro %s: %w", name, shiftTokenError(err, -1))
+	}
+
+	opts := Modifiers{}
+	for _, tok := range append(argToks, mods...) {
+		key, val, err := fg.parseToken(tok)
+		if err != nil {
+			return LazyOperation{}, fmt.Errorf("macro %s: %w", name, shiftTokenError(err, -1))
+		}
+		opts[key] = val
+	}
+
+	return LazyOperation{
+		Name: name,
+		Opts: opts,
+	}, nil
 }
 
 func (fg FlatGrammar) Order(lazyOps []LazyOperation) ([]LazyOperation, error) {
-	// $$$TODO $$$SIMON: Implement flat grammar operation ordering according to grammar rules.
-	return nil, nil
+	// Flat grammars execute operations in declaration order.
+	ordered := make([]LazyOperation, len(lazyOps))
+	copy(ordered, lazyOps)
+	return ordered, nil
+}
+
+// parseToken matches a single token against the operation pattern,
+// returning its key and unquoted value.
+func (fg FlatGrammar) parseToken(tok string) (string, string, error) {
+	m := fg.OperationPattern.FindStringSubmatch(tok)
+	if m == nil {
+		return "", "", &TokenError{Token: tok, Hint: "expected `key` or `key=value`", Err: ErrTagMalformed}
+	}
+
+	val := m[4]
+	switch {
+	case m[2] != "":
+		val = m[2]
+	case m[3] != "":
+		val = m[3]
+	}
+
+	return m[1], val, nil
+}
+
+func (fg FlatGrammar) modifierSpec(opkey, modkey string) (ModifierSpec, bool) {
+	if opSpec, ok := fg.opSpecs[opkey]; ok {
+		if spec, ok := opSpec.modSpecs[modkey]; ok {
+			return spec, true
+		}
+	}
+	spec, ok := fg.sharedMods[modkey]
+	return spec, ok
+}
+
+// modifierKeys returns the sorted keys of the modifiers of opkey,
+// shared modifiers included.
+func (fg FlatGrammar) modifierKeys(opkey string) []string {
+	var keys []string
+	if opSpec, ok := fg.opSpecs[opkey]; ok {
+		for modkey := range opSpec.modSpecs {
+			keys = append(keys, modkey)
+		}
+	}
+	for modkey := range fg.sharedMods {
+		keys = append(keys, modkey)
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// modifierHint suggests how to write the modifier modkey of opkey.
+func (fg FlatGrammar) modifierHint(opkey, modkey string) string {
+	switch fg.modformat {
+	case ModFormatKeyOnly:
+		return fmt.Sprintf("write `%s` without a value", modkey)
+	case ModFormatKVOnly:
+		if spec, ok := fg.modifierSpec(opkey, modkey); ok {
+			return fmt.Sprintf("write `%s=<%s>`", modkey, spec.kind)
+		}
+		return fmt.Sprintf("write `%s=<value>`", modkey)
+	}
+
+	if spec, ok := fg.modifierSpec(opkey, modkey); ok {
+		return fmt.Sprintf("`%s` value must be %s", modkey, spec.kind)
+	}
+	return ""
+}
+
+func (fg FlatGrammar) isModifier(opkey, modkey string) bool {
+	_, ok := fg.modifierSpec(opkey, modkey)
+	return ok
+}
+
+func (fg FlatGrammar) validateModifier(opkey, modkey, val string, hasValue bool) error {
+	switch {
+	case hasValue && fg.modformat == ModFormatKeyOnly:
+		return fmt.Errorf("%w: modifier %s must be key-only", ErrModInvalid, modkey)
+	case !hasValue && fg.modformat == ModFormatKVOnly:
+		return fmt.Errorf("%w: modifier %s requires a value", ErrModInvalid, modkey)
+	}
+
+	spec, ok := fg.modifierSpec(opkey, modkey)
+	if !ok {
+		// Unspecified modifiers are passed through to the operation as-is.
+		return nil
+	}
+
+	if !hasValue {
+		if spec.kind != ModKindBool {
+			return fmt.Errorf("%w: key-only modifier %s must be %s, is %s", ErrModInvalid, modkey, ModKindBool, spec.kind)
+		}
+		return nil
+	}
+
+	if err := spec.kind.check(val); err != nil {
+		return fmt.Errorf("%w: modifier %s: %w", ErrModInvalid, modkey, err)
+	}
+
+	return nil
+}
+
+// splitTokens splits s on sep, ignoring separators inside single or
+// double quoted values, and inside the arguments of macro calls, e.g.
+// `username(min=3,max=32)`. Tokens are trimmed, empty tokens are dropped.
+func splitTokens(s string, sep byte) ([]string, error) {
+	tokens, _, err := splitTokenOffsets(s, sep)
+	return tokens, err
+}
+
+// splitTokenOffsets is splitTokens, also returning the byte offset in s
+// of each token.
+func splitTokenOffsets(s string, sep byte) ([]string, []int, error) {
+	var (
+		tokens []string
+		offs   []int
+		quote  byte
+		start  int
+		depth  int
+	)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' && (depth > 0 || isOpKey(strings.TrimSpace(s[start:i]))):
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case depth > 0:
+		case c == sep:
+			if tok, off := trimOffset(s, start, i); tok != "" {
+				tokens = append(tokens, tok)
+				offs = append(offs, off)
+			}
+			start = i + 1
+		}
+	}
+
+	tok, off := trimOffset(s, start, len(s))
+	if quote != 0 {
+		return nil, nil, &TokenError{Token: tok, Offset: off, Hint: "close the quote", Err: fmt.Errorf("%w: unterminated quote", ErrTagMalformed)}
+	}
+	if depth != 0 {
+		return nil, nil, &TokenError{Token: tok, Offset: off, Hint: "close the parenthesis", Err: fmt.Errorf("%w: unterminated macro call", ErrTagMalformed)}
+	}
+
+	if tok != "" {
+		tokens = append(tokens, tok)
+		offs = append(offs, off)
+	}
+
+	return tokens, offs, nil
+}
+
+// trimOffset returns s[start:end] trimmed of spaces, and its offset in s.
+func trimOffset(s string, start, end int) (string, int) {
+	sub := s[start:end]
+	trimmed := strings.TrimLeftFunc(sub, unicode.IsSpace)
+	return strings.TrimRightFunc(trimmed, unicode.IsSpace), start + len(sub) - len(trimmed)
+}
+
+// cutCall cuts a macro call token, `<name>(<args>)`, into its name and
+// arguments.
+func cutCall(tok string) (name, args string, ok bool) {
+	i := strings.IndexByte(tok, '(')
+	if i <= 0 || !strings.HasSuffix(tok, ")") || !isOpKey(tok[:i]) {
+		return "", "", false
+	}
+	return tok[:i], tok[i+1 : len(tok)-1], true
+}
+
+// isOpKey reports whether s is a valid operation key, e.g. "validate.email".
+func isOpKey(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '.' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// tokenKey returns the key of a `<key>` or `<key>=<value>` token.
+func tokenKey(tok string) string {
+	key, _, _ := strings.Cut(tok, "=")
+	return strings.TrimSpace(key)
 }
 
 var (
@@ -306,6 +706,8 @@ func (mf ModifierFormat) String() string {
 		return "Key-Value"
 	case ModFormatKeyOnly:
 		return "Key-Only"
+	case ModFormatMixed:
+		return "Mixed"
 	default:
 		return "Unknown"
 	}
@@ -383,10 +785,78 @@ func (mk ModifierKind) String() string {
 	}
 }
 
+// check reports whether val can be parsed as a modifier of kind mk.
+func (mk ModifierKind) check(val string) error {
+	var err error
+	switch mk {
+	case ModKindBool:
+		_, err = parseModBool(val)
+	case ModKindInt:
+		_, err = strconv.ParseInt(val, 10, 64)
+	case ModKindUInt:
+		_, err = strconv.ParseUint(val, 10, 64)
+	case ModKindFloat:
+		_, err = strconv.ParseFloat(val, 64)
+	case ModKindComplex:
+		_, err = strconv.ParseComplex(val, 128)
+	case ModKindString, ModKindConverted:
+	default:
+		err = fmt.Errorf("unknown modifier kind %d", mk)
+	}
+	if err != nil {
+		return fmt.Errorf("value %q is not %s: %w", val, mk, err)
+	}
+	return nil
+}
+
+// parseModBool parses the boolean spellings accepted by [ModKindBool].
+func parseModBool(val string) (bool, error) {
+	switch strings.ToLower(val) {
+	case "", "true", "1", "yes":
+		return true, nil
+	case "false", "0", "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean %q", val)
+	}
+}
+
 type ModifierSpec struct {
 	modkey string
 	use    ModifierUse
 	kind   ModifierKind
+
+	def        string
+	hasDefault bool
+	required   bool
+}
+
+// NewModifierSpec specifies the modifier modkey, e.g. for a [DescribedOperation].
+func NewModifierSpec(modkey string, use ModifierUse, kind ModifierKind) ModifierSpec {
+	return ModifierSpec{modkey: modkey, use: use, kind: kind}
+}
+
+// WithDefault returns the spec with a default raw value, set by the
+// grammar when the modifier is absent from a tag.
+func (s ModifierSpec) WithDefault(val string) ModifierSpec {
+	s.def, s.hasDefault = val, true
+	return s
+}
+
+// AsRequired returns the spec of a modifier that tags must set.
+func (s ModifierSpec) AsRequired() ModifierSpec {
+	s.required = true
+	return s
+}
+
+func (s ModifierSpec) Key() string        { return s.modkey }
+func (s ModifierSpec) Use() ModifierUse   { return s.use }
+func (s ModifierSpec) Kind() ModifierKind { return s.kind }
+func (s ModifierSpec) Required() bool     { return s.required }
+
+// Default returns the default raw value, and whether there is one.
+func (s ModifierSpec) Default() (string, bool) {
+	return s.def, s.hasDefault
 }
 
 type OperationSpec struct {
@@ -435,6 +905,7 @@ type GrammarConfig interface {
 	SetKey(key string) GrammarConfig
 	SetDescription(desc string) GrammarConfig
 	SetWalkType(walkType WalkType) GrammarConfig
+	SetOpArity(arity OpArity) GrammarConfig
 	SetCombiner(combiner Combiner) GrammarConfig
 	SetApplier(applier Applier) GrammarConfig
 	SetTransformer(transformer Transformer) GrammarConfig
@@ -442,6 +913,9 @@ type GrammarConfig interface {
 	SetModifierFormat(format ModifierFormat) GrammarConfig
 	SetSharedModifier(modkey string, use ModifierUse, kind ModifierKind) GrammarConfig
 	SetCustomModifier(opkey string, modkey string, use ModifierUse, kind ModifierKind) GrammarConfig
+	SetDescribedOperation(opkey string, op DescribedOperation) GrammarConfig
+	SetDefaultOperation(opkey string) GrammarConfig
+	SetImplicitOperation(opkey string) GrammarConfig
 	SetFlatStructure() FlatGrammarConfig
 	SetHierarchyStructure() HierarchyGrammarConfig
 }
@@ -463,6 +937,7 @@ type grammarConfig struct {
 	key         string
 	desc        string
 	walkType    WalkType
+	opArity     OpArity
 	combiner    Combiner
 	applier     Applier
 	transformer Transformer
@@ -477,6 +952,12 @@ type grammarConfig struct {
 	// Un-Keyed operations use default modifier formats, and
 	// function under lazy resolution as usual.
 	customOpSpecs map[string]OperationSpec
+
+	// Operation implied when a tag starts with one of its modifiers,
+	// e.g. `env:"name=HOST"` for default operation `env`.
+	defaultOp string
+	// Operation applied to exported fields without a tag.
+	implicitOp string
 }
 
 type flatGrammarConfig struct {
@@ -509,6 +990,11 @@ func (cfg *grammarConfig) SetWalkType(walkType WalkType) GrammarConfig {
 	return cfg
 }
 
+func (cfg *grammarConfig) SetOpArity(arity OpArity) GrammarConfig {
+	cfg.opArity = arity
+	return cfg
+}
+
 func (cfg *grammarConfig) SetCombiner(combiner Combiner) GrammarConfig {
 	cfg.combiner = combiner
 	return cfg
@@ -552,6 +1038,7 @@ func (cfg *grammarConfig) SetSharedModifier(modkey string, use ModifierUse, kind
 		spec.kind = kind
 	}
 
+	cfg.sharedMods[modkey] = spec
 	return cfg
 }
 
@@ -585,6 +1072,27 @@ func (cfg *grammarConfig) SetCustomModifier(opkey string, modkey string, use Mod
 	return cfg
 }
 
+// SetDescribedOperation sets the modifiers of opkey from the modifier
+// specs of op, with their defaults and whether they are required.
+func (cfg *grammarConfig) SetDescribedOperation(opkey string, op DescribedOperation) GrammarConfig {
+	for _, spec := range op.Doc().Modifiers {
+		cfg.SetCustomModifier(opkey, spec.modkey, spec.use, spec.kind)
+		opSpec := cfg.customOpSpecs[opkey]
+		opSpec.modSpecs[spec.modkey] = spec
+	}
+	return cfg
+}
+
+func (cfg *grammarConfig) SetDefaultOperation(opkey string) GrammarConfig {
+	cfg.defaultOp = opkey
+	return cfg
+}
+
+func (cfg *grammarConfig) SetImplicitOperation(opkey string) GrammarConfig {
+	cfg.implicitOp = opkey
+	return cfg
+}
+
 func (cfg *grammarConfig) SetFlatStructure() FlatGrammarConfig {
 	return &flatGrammarConfig{
 		grammarConfig: *cfg,
@@ -645,8 +1153,158 @@ func (cfg *flatGrammarConfig) Build() (Grammar, error) {
 		return nil, err
 	}
 
-	// $$$TODO $$$SIMON: Implement flat grammar building.
-	return nil, nil
+	tagPattern, opPattern, err := cfg.compilePatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validateModifiers(); err != nil {
+		return nil, err
+	}
+
+	for opkey := range cfg.customOpSpecs {
+		if opkey == "" {
+			return nil, GrammarBuildError{
+				Stage:  StageOperationValidation,
+				Reason: "Custom modifier registered for empty operation key",
+				Value:  opkey,
+			}
+		}
+	}
+
+	base, err := cfg.finalize()
+	if err != nil {
+		return nil, err
+	}
+
+	return &FlatGrammar{
+		baseGrammarData:  base,
+		TagPattern:       tagPattern,
+		OperationPattern: opPattern,
+		format:           cfg.format,
+		separator:        cfg.separator,
+		arity:            cfg.arity,
+		modformat:        cfg.modformat,
+		defaultOp:        cfg.defaultOp,
+		implicitOp:       cfg.implicitOp,
+		sharedMods:       cfg.sharedMods,
+		opSpecs:          cfg.customOpSpecs,
+	}, nil
+}
+
+func (cfg *flatGrammarConfig) compilePatterns() (*regexp.Regexp, *regexp.Regexp, error) {
+	var tagPattern *regexp.Regexp
+	if cfg.format == FlatFormatEnclosed {
+		open, close := regexp.QuoteMeta(string(cfg.separator[0])), regexp.QuoteMeta(string(cfg.separator[1]))
+		p, err := regexp.Compile(open + `([^` + close + `]*)` + close)
+		if err != nil {
+			return nil, nil, GrammarBuildError{
+				Stage:  StagePatternCompilation,
+				Reason: "Compiling tag pattern: " + err.Error(),
+				Value:  cfg.separator,
+			}
+		}
+		tagPattern = p
+	}
+
+	// Tokens are already split on the separator, so an unquoted value
+	// runs to the end of the token.
+	opPattern, err := regexp.Compile(`^([a-zA-Z_][a-zA-Z0-9_.\-]*)(?:=(?:"((?:[^"\\]|\\.)*)"|'((?:[^'\\]|\\.)*)'|(.*)))?$`)
+	if err != nil {
+		return nil, nil, GrammarBuildError{
+			Stage:  StagePatternCompilation,
+			Reason: "Compiling operation pattern: " + err.Error(),
+			Value:  cfg.separator,
+		}
+	}
+
+	return tagPattern, opPattern, nil
+}
+
+func (cfg *grammarConfig) validateModifiers() error {
+	check := func(spec ModifierSpec) error {
+		if spec.kind.String() == "Unknown" || spec.use.String() == "Unknown" {
+			return GrammarBuildError{
+				Stage:  StageModifierValidation,
+				Reason: "Unknown modifier kind or use",
+				Value:  spec.modkey,
+			}
+		}
+		if cfg.modformat == ModFormatKeyOnly && spec.kind != ModKindBool {
+			return GrammarBuildError{
+				Stage:  StageModifierValidation,
+				Reason: "Key-only modifier format requires bool modifiers",
+				Value:  spec.modkey,
+			}
+		}
+		return nil
+	}
+
+	for _, spec := range cfg.sharedMods {
+		if err := check(spec); err != nil {
+			return err
+		}
+	}
+
+	for _, opSpec := range cfg.customOpSpecs {
+		for _, spec := range opSpec.modSpecs {
+			if err := check(spec); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// finalize validates the common configuration and produces the
+// data shared by every grammar structure.
+func (cfg *grammarConfig) finalize() (baseGrammarData, error) {
+	if cfg.key == "" {
+		return baseGrammarData{}, GrammarBuildError{
+			Stage:  StageFinalization,
+			Reason: "Grammar key must not be empty",
+			Value:  cfg.key,
+		}
+	}
+
+	if cfg.opArity == 0 {
+		cfg.opArity = OpUnary
+	}
+
+	base := baseGrammarData{
+		key:         cfg.key,
+		description: cfg.desc,
+		walkType:    cfg.walkType,
+		opArity:     cfg.opArity,
+		combiner:    cfg.combiner,
+		applier:     cfg.applier,
+		transformer: cfg.transformer,
+	}
+
+	switch cfg.walkType {
+	case CombineWalk:
+		if cfg.combiner == nil {
+			return baseGrammarData{}, GrammarBuildError{
+				Stage:  StageFinalization,
+				Reason: "CombineWalk grammar requires a combiner",
+				Value:  cfg.walkType,
+			}
+		}
+	case ApplyWalk:
+		if cfg.applier == nil {
+			base.applier = ReflectSetterApplier{}
+		}
+	case TransformWalk:
+	default:
+		return baseGrammarData{}, GrammarBuildError{
+			Stage:  StageFinalization,
+			Reason: "Unknown walk type",
+			Value:  cfg.walkType,
+		}
+	}
+
+	return base, nil
 }
 
 // $$$TODO $$$SIMON: Implement hierarchy grammar building.
@@ -1,5 +1,10 @@
 package recipe
 
+import (
+	"reflect"
+	"testing"
+)
+
 var (
 	simpleStringGrammarKey       = "simple_string_grammar"
 	simpleBoolCombinerGrammarKey = "simple_bool_grammar"
@@ -64,3 +69,48 @@ type test_SimpleBoolGrammar struct{ test_BaseGrammar }
 //   - Only one operation per tag
 //   - No options ([FirstSuccess] strategy only)
 type test_SimpleSelfFieldGrammar struct{ test_BaseGrammar }
+
+func TestFlatGrammarParse(t *testing.T) {
+	grammar, err := NewGrammarConfig().
+		SetKey("v").
+		SetWalkType(CombineWalk).
+		SetCombiner(BoolAndCombiner{}).
+		SetOpArity(OpUnary).
+		SetArity(GrammarArityVariadic).
+		SetModifierFormat(ModFormatMixed).
+		SetSharedModifier("msg", ModifierUseOperation, ModKindString).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tag  string
+		want []LazyOperation
+	}{
+		{"required", []LazyOperation{{Name: "required", Opts: Modifiers{}}}},
+		{"min=8", []LazyOperation{{Name: "min", Opts: Modifiers{"min": "8"}}}},
+		{"oneof='red green'", []LazyOperation{{Name: "oneof", Opts: Modifiers{"oneof": "red green"}}}},
+		{"required,min=3,max=32", []LazyOperation{
+			{Name: "required", Opts: Modifiers{}},
+			{Name: "min", Opts: Modifiers{"min": "3"}},
+			{Name: "max", Opts: Modifiers{"max": "32"}},
+		}},
+		{"min=3,msg=short", []LazyOperation{{Name: "min", Opts: Modifiers{"min": "3", "msg": "short"}}}},
+		{"username(min=3)", []LazyOperation{{Name: "username", Opts: Modifiers{"min": "3"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := ParseTag(grammar, tt.tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsed %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+// Package lint provides an analyzer reporting malformed recipe struct tags
+// at compile time.
+//
+// Grammars and registered operations are read from a manifest, written
+// by the application with [recipe.Executor.Manifest]:
+//
+//	exec.Manifest().WriteJSON(f)
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"recipe"
+)
+
+const doc = `check recipe struct tags against a manifest
+
+Reports, at their position in the tag:
+  - malformed tags, enclosures and modifiers
+  - modifier values not of their declared kind
+  - operations missing from the manifest
+  - operations on fields of a kind they do not accept`
+
+var ErrNoManifest = errors.New("no manifest, set -manifest")
+
+// Analyzer lints the tags of every struct type declared in a package.
+var Analyzer = &analysis.Analyzer{
+	Name:     "recipelint",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var manifestPath string
+
+func init() {
+	Analyzer.Flags.StringVar(&manifestPath, "manifest", "", "path of the JSON recipe manifest")
+}
+
+// linters caches the linter of each manifest path, across packages.
+var linters sync.Map
+
+func loadLinter(path string) (*recipe.TagLinter, error) {
+	if tl, ok := linters.Load(path); ok {
+		return tl.(*recipe.TagLinter), nil
+	}
+
+	m, err := recipe.LoadManifest(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading manifest: %w", err)
+	}
+
+	tl, err := recipe.NewTagLinter(m)
+	if err != nil {
+		return nil, fmt.Errorf("loading manifest %s: %w", path, err)
+	}
+
+	linters.Store(path, tl)
+	return tl, nil
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if manifestPath == "" {
+		return nil, ErrNoManifest
+	}
+
+	tl, err := loadLinter(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.Preorder([]ast.Node{(*ast.TypeSpec)(nil)}, func(n ast.Node) {
+		ts := n.(*ast.TypeSpec)
+		if st, ok := ts.Type.(*ast.StructType); ok {
+			checkStruct(pass, tl, st, ts.Name.Name)
+		}
+	})
+
+	return nil, nil
+}
+
+// checkStruct lints the tags of the fields of st, at path from its
+// declared type. Anonymous struct fields are walked.
+func checkStruct(pass *analysis.Pass, tl *recipe.TagLinter, st *ast.StructType, path string) {
+	for _, field := range st.Fields.List {
+		names := fieldNames(field)
+
+		if nested, ok := field.Type.(*ast.StructType); ok {
+			for _, name := range names {
+				checkStruct(pass, tl, nested, path+"."+name)
+			}
+		}
+
+		if field.Tag == nil {
+			continue
+		}
+
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		kind := kindOf(pass.TypesInfo.TypeOf(field.Type))
+
+		for _, key := range tl.Keys() {
+			value, ok := reflect.StructTag(tag).Lookup(key)
+			if !ok {
+				continue
+			}
+
+			for _, name := range names {
+				for _, d := range tl.Lint(key, path+"."+name, value, kind) {
+					report(pass, field.Tag, key, d)
+				}
+			}
+		}
+	}
+}
+
+// report reports d at the offending token of the tag literal lit,
+// or at lit if it cannot be located.
+func report(pass *analysis.Pass, lit *ast.BasicLit, key string, d recipe.Diagnostic) {
+	pos, end := lit.Pos(), lit.End()
+
+	if d.Offset >= 0 {
+		start, ok1 := tagOffset(lit.Value, key, d.Offset)
+		stop, ok2 := tagOffset(lit.Value, key, d.Offset+max(len(d.Op), 1))
+		if ok1 && ok2 {
+			pos, end = lit.Pos()+token.Pos(start), lit.Pos()+token.Pos(stop)
+		}
+	}
+
+	msg := fmt.Sprintf("%s: %v", d.Path, d.Err)
+	if d.Suggestion != "" {
+		msg += "; " + d.Suggestion
+	}
+
+	pass.Report(analysis.Diagnostic{
+		Pos:      pos,
+		End:      end,
+		Category: key,
+		Message:  msg,
+	})
+}
+
+// tagOffset returns the offset in lit, a struct tag literal, of the byte
+// at offset off in the value of key, as returned by reflect.StructTag.Get.
+func tagOffset(lit, key string, off int) (int, bool) {
+	if len(lit) < 2 {
+		return 0, false
+	}
+
+	litQuote := lit[0]
+	body := lit[1 : len(lit)-1]
+	tag := body
+	if litQuote != '`' {
+		var err error
+		if tag, err = strconv.Unquote(lit); err != nil {
+			return 0, false
+		}
+	}
+
+	// Find the quoted value of key as reflect.StructTag.Lookup does,
+	// so that a longer key ending in key does not match.
+	i := 0
+	for i < len(tag) {
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == start || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return 0, false
+		}
+		name := tag[start:i]
+		i += 2
+
+		valStart := i
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return 0, false
+		}
+		value := tag[valStart:i]
+		i++
+
+		if name != key {
+			continue
+		}
+
+		inTag, ok := rawOffset(value, '"', off)
+		if !ok {
+			return 0, false
+		}
+		if litQuote == '`' {
+			return 1 + valStart + inTag, true
+		}
+
+		inLit, ok := rawOffset(body, litQuote, valStart+inTag)
+		return 1 + inLit, ok
+	}
+
+	return 0, false
+}
+
+// rawOffset returns the offset in s, the body of a string quoted with
+// quote, of the byte at offset off of its unquoted value.
+func rawOffset(s string, quote byte, off int) (int, bool) {
+	i, n := 0, 0
+	for n < off {
+		if i >= len(s) {
+			return 0, false
+		}
+
+		r, multibyte, tail, err := strconv.UnquoteChar(s[i:], quote)
+		if err != nil {
+			return 0, false
+		}
+		if multibyte {
+			n += utf8.RuneLen(r)
+		} else {
+			n++
+		}
+		i = len(s) - len(tail)
+	}
+	return i, n == off
+}
+
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		// Embedded field, named after its type
+		expr := field.Type
+		if star, ok := expr.(*ast.StarExpr); ok {
+			expr = star.X
+		}
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return []string{e.Name}
+		case *ast.SelectorExpr:
+			return []string{e.Sel.Name}
+		case *ast.IndexExpr:
+			return fieldNames(&ast.Field{Type: e.X})
+		case *ast.IndexListExpr:
+			return fieldNames(&ast.Field{Type: e.X})
+		}
+		return []string{types.ExprString(field.Type)}
+	}
+
+	names := make([]string, len(field.Names))
+	for i, name := range field.Names {
+		names[i] = name.Name
+	}
+	return names
+}
+
+// kindOf returns the reflect.Kind of values of type t.
+func kindOf(t types.Type) reflect.Kind {
+	if t == nil {
+		return reflect.Invalid
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return basicKinds[u.Kind()]
+	case *types.Pointer:
+		return reflect.Pointer
+	case *types.Slice:
+		return reflect.Slice
+	case *types.Array:
+		return reflect.Array
+	case *types.Map:
+		return reflect.Map
+	case *types.Chan:
+		return reflect.Chan
+	case *types.Signature:
+		return reflect.Func
+	case *types.Interface:
+		return reflect.Interface
+	case *types.Struct:
+		return reflect.Struct
+	default:
+		return reflect.Invalid
+	}
+}
+
+var basicKinds = map[types.BasicKind]reflect.Kind{
+	types.Bool:          reflect.Bool,
+	types.Int:           reflect.Int,
+	types.Int8:          reflect.Int8,
+	types.Int16:         reflect.Int16,
+	types.Int32:         reflect.Int32,
+	types.Int64:         reflect.Int64,
+	types.Uint:          reflect.Uint,
+	types.Uint8:         reflect.Uint8,
+	types.Uint16:        reflect.Uint16,
+	types.Uint32:        reflect.Uint32,
+	types.Uint64:        reflect.Uint64,
+	types.Uintptr:       reflect.Uintptr,
+	types.Float32:       reflect.Float32,
+	types.Float64:       reflect.Float64,
+	types.Complex64:     reflect.Complex64,
+	types.Complex128:    reflect.Complex128,
+	types.String:        reflect.String,
+	types.UnsafePointer: reflect.UnsafePointer,
+}
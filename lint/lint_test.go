@@ -0,0 +1,70 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"recipe"
+)
+
+func writeManifest(t *testing.T) string {
+	t.Helper()
+
+	grammar, err := recipe.NewMaskGrammar(recipe.CombineWalk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := recipe.NewOpRegistry()
+	if err := recipe.RegisterMaskOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	exec := recipe.NewExecutor(reg, recipe.NewBuilder(grammar))
+
+	path := filepath.Join(t.TempDir(), "recipe.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := exec.Manifest().WriteJSON(f); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAnalyzer(t *testing.T) {
+	if err := Analyzer.Flags.Set("manifest", writeManifest(t)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Analyzer.Flags.Set("manifest", "") })
+
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+
+	// Diagnostics point at the offending token of the tag
+	want := map[string]string{
+		"Phone":       "char",
+		"Note":        "char",
+		"Quote":       "char",
+		"Card":        "crad",
+		"Address.Zip": "density=2x",
+	}
+	for _, res := range results {
+		for _, d := range res.Diagnostics {
+			path, _, _ := strings.Cut(strings.TrimPrefix(d.Message, "User."), ": ")
+
+			pos, end := res.Pass.Fset.Position(d.Pos), res.Pass.Fset.Position(d.End)
+			src, err := os.ReadFile(pos.Filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(src[pos.Offset:end.Offset]); got != want[path] {
+				t.Errorf("%s: reported at %q, want %q", path, got, want[path])
+			}
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package a
+
+type User struct {
+	Email string `mask:"email"`
+	Phone string `mask:"phone,char"` // want `Phone: .*requires a value`
+	Card  string `mask:"crad"`       // want "Card: .*did you mean `card`"
+	Name  string `json:"name" mask:"redact"`
+	Note  string `premask:"char" mask:"email,char"` // want `Note: .*requires a value`
+	Quote string "mask:\"email,char\""              // want `Quote: .*requires a value`
+
+	Address struct {
+		Zip string `mask:"iban,density=2x"` // want `Address.Zip: .*density`
+	}
+}
@@ -0,0 +1,182 @@
+package recipe
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LogValuer wraps a tagged struct so that logging it through [log/slog]
+// emits its fields processed by the executor's recipe.
+//
+// Intended for mask grammars, see [NewMaskGrammar]:
+//   - tagged fields are logged as the result of their operations
+//   - untagged fields are logged as-is
+//   - fields tagged `-` are omitted
+//
+// Nested structs are logged as groups. Pointers, slices and maps are
+// not walked by recipes: unless tagged, the structs they hold are logged
+// through their own recipe.
+type LogValuer struct {
+	exec *Executor
+	key  string
+	v    any
+}
+
+// NewLogValuer wraps v, a struct or a pointer to a struct, for logging.
+//
+// Recipes are retrieved through [Builder.GetOrBuild], so only the first
+// log call for a type builds and resolves its recipe.
+func NewLogValuer(exec *Executor, v any) LogValuer {
+	return LogValuer{exec: exec, v: v}
+}
+
+// NewLogValuerWith is [NewLogValuer] for the grammar of the given key,
+// e.g. [MaskGrammarKey] when exec has several grammars.
+func NewLogValuerWith(exec *Executor, key string, v any) LogValuer {
+	return LogValuer{exec: exec, key: key, v: v}
+}
+
+var __ctc__LogValuer_impl_LogValuer slog.LogValuer = LogValuer{}
+
+func (lv LogValuer) LogValue() slog.Value {
+	rv := reflect.ValueOf(lv.v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return slog.AnyValue(nil)
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return slog.AnyValue(lv.v)
+	}
+
+	rcp, err := lv.exec.resolveRecipeWith(lv.key, rv.Type())
+	if err != nil {
+		return slog.StringValue(fmt.Sprintf("!ERROR: %v", err))
+	}
+
+	return slog.GroupValue(lv.attrs(rcp.Grammar, rcp.Root, rv, 0)...)
+}
+
+// maxLogDepth bounds the reflective logging of values the recipe does not
+// walk, so that cyclic pointers do not recurse forever.
+const maxLogDepth = 16
+
+// attrs builds the attributes of the struct value sv described by eTree.
+// Fields tagged `-` under key are omitted.
+func (lv LogValuer) attrs(key string, eTree *ExecTree, sv reflect.Value, depth int) []slog.Attr {
+	st := sv.Type()
+
+	nodes := make(map[int]*ExecTree, len(eTree.Children))
+	for _, cTree := range eTree.Children {
+		nodes[cTree.fieldIdx] = cTree
+	}
+
+	attrs := make([]slog.Attr, 0, st.NumField())
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if !field.IsExported() || field.Tag.Get(key) == "-" {
+			continue
+		}
+
+		fv := sv.Field(i)
+		cTree, ok := nodes[i]
+
+		switch {
+		case ok && cTree.fieldKind == reflect.Struct && cTree.hasChild():
+			attrs = append(attrs, slog.Attr{Key: field.Name, Value: slog.GroupValue(lv.attrs(key, cTree, fv, depth+1)...)})
+		case ok && cTree.hasOperation():
+			attrs = append(attrs, slog.Any(field.Name, lv.apply(cTree, fv.Interface())))
+		default:
+			attrs = append(attrs, slog.Attr{Key: field.Name, Value: lv.value(key, fv, depth+1)})
+		}
+	}
+
+	return attrs
+}
+
+// value logs v, which the recipe does not walk. Structs it holds are
+// logged through their own recipe of the grammar key.
+func (lv LogValuer) value(key string, v reflect.Value, depth int) slog.Value {
+	if depth > maxLogDepth {
+		return slog.StringValue("!ERROR: too deep")
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		rcp, err := lv.exec.resolveRecipeWith(key, v.Type())
+		if err != nil {
+			return slog.StringValue(fmt.Sprintf("!ERROR: %v", err))
+		}
+		return slog.GroupValue(lv.attrs(key, rcp.Root, v, depth)...)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return slog.AnyValue(nil)
+		}
+		if !holdsStruct(v.Type()) && v.Kind() == reflect.Pointer {
+			break
+		}
+		return lv.value(key, v.Elem(), depth+1)
+	case reflect.Slice, reflect.Array:
+		if !holdsStruct(v.Type().Elem()) {
+			break
+		}
+		attrs := make([]slog.Attr, v.Len())
+		for i := range attrs {
+			attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: lv.value(key, v.Index(i), depth+1)}
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Map:
+		if !holdsStruct(v.Type().Elem()) {
+			break
+		}
+		attrs := make([]slog.Attr, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			attrs = append(attrs, slog.Attr{Key: fmt.Sprint(iter.Key().Interface()), Value: lv.value(key, iter.Value(), depth+1)})
+		}
+		slices.SortFunc(attrs, func(a, b slog.Attr) int { return strings.Compare(a.Key, b.Key) })
+		return slog.GroupValue(attrs...)
+	}
+
+	if !v.CanInterface() {
+		return slog.AnyValue(nil)
+	}
+	return slog.AnyValue(v.Interface())
+}
+
+// holdsStruct reports whether values of t may hold a struct, whose
+// fields may be tagged `-`.
+func holdsStruct(t reflect.Type) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Struct, reflect.Interface:
+			return true
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+}
+
+// apply runs the operations of a leaf node on v. Errors never expose v.
+func (lv LogValuer) apply(eTree *ExecTree, v any) any {
+	for _, operation := range eTree.Operations {
+		res, err := operation.Op.Execute(operation.Opts, v)
+		if err != nil {
+			return fmt.Sprintf("!ERROR: operation %s: %v", operation.Name, err)
+		}
+		v = res
+
+		if eTree.OpStrategy == FirstSuccess {
+			break
+		}
+	}
+	return v
+}
@@ -0,0 +1,97 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type loggedCreds struct {
+	User     string
+	Password string `mask:"-"`
+}
+
+type loggedUser struct {
+	Name    string `mask:"redact"`
+	Email   string `mask:"email"`
+	Plan    string
+	Secret  string `mask:"-"`
+	Creds   loggedCreds
+	CredPtr *loggedCreds
+	History []loggedCreds
+	hidden  string
+}
+
+// logJSON logs v through a JSON handler and returns the decoded "v".
+func logJSON(t *testing.T, v slog.LogValuer) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("test", "v", v)
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("decoding %s: %v", buf.String(), err)
+	}
+	m, _ := rec["v"].(map[string]any)
+	return m
+}
+
+func TestLogValuer(t *testing.T) {
+	exec := newMaskExecutor(t, CombineWalk)
+
+	u := loggedUser{
+		Name:    "Ada",
+		Email:   "ada@example.com",
+		Plan:    "pro",
+		Secret:  "s3cr3t",
+		Creds:   loggedCreds{User: "ada", Password: "hunter2"},
+		CredPtr: &loggedCreds{User: "ada", Password: "hunter2"},
+		History: []loggedCreds{{User: "old", Password: "hunter1"}},
+		hidden:  "x",
+	}
+
+	got := logJSON(t, NewLogValuer(exec, &u))
+	want := map[string]any{
+		"Name":    "[REDACTED]",
+		"Email":   "***@example.com",
+		"Plan":    "pro",
+		"Creds":   map[string]any{"User": "ada"},
+		"CredPtr": map[string]any{"User": "ada"},
+		"History": map[string]any{"0": map[string]any{"User": "old"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("logged %v, want %v", got, want)
+	}
+}
+
+func TestLogValuerNil(t *testing.T) {
+	exec := newMaskExecutor(t, CombineWalk)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("test", "v", NewLogValuer(exec, (*loggedUser)(nil)))
+	if !strings.Contains(buf.String(), `"v":null`) {
+		t.Errorf("logged %s, want a null v", buf.String())
+	}
+}
+
+type loggedNode struct {
+	Name string `mask:"redact"`
+	Next *loggedNode
+}
+
+func TestLogValuerCycle(t *testing.T) {
+	exec := newMaskExecutor(t, CombineWalk)
+
+	n := &loggedNode{Name: "a"}
+	n.Next = n
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("test", "v", NewLogValuer(exec, n))
+	if strings.Contains(buf.String(), `"a"`) || !strings.Contains(buf.String(), "too deep") {
+		t.Errorf("logged %s", buf.String())
+	}
+}
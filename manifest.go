@@ -0,0 +1,343 @@
+package recipe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrManifestInvalid = fmt.Errorf("invalid manifest")
+	ErrFieldKind       = fmt.Errorf("unsupported field kind")
+)
+
+// Manifest describes the grammars and registered operations of an
+// [Executor], for tooling that cannot run it, such as static analyzers.
+//
+// See: [Executor.Manifest], [NewTagLinter]
+type Manifest struct {
+	Grammars   []GrammarManifest   `json:"grammars"`
+	Operations []OperationManifest `json:"operations"`
+}
+
+// GrammarManifest describes a flat grammar, see [FlatGrammarConfig].
+//
+// Enumerations are written in lower case, e.g. "combine", "delimited", "variadic",
+// "mixed", and modifier kinds as "bool", "int", "uint", "float",
+// "complex", "string" or "converted".
+type GrammarManifest struct {
+	Key            string `json:"key"`
+	WalkType       string `json:"walkType,omitempty"`
+	Format         string `json:"format"`
+	Separator      string `json:"separator"`
+	Arity          string `json:"arity,omitempty"`
+	ModifierFormat string `json:"modifierFormat,omitempty"`
+	DefaultOp      string `json:"defaultOp,omitempty"`
+	ImplicitOp     string `json:"implicitOp,omitempty"`
+
+	// SharedModifiers maps modifier keys to their kind.
+	SharedModifiers map[string]string `json:"sharedModifiers,omitempty"`
+	// Modifiers maps operation keys to their modifier keys and kinds.
+	Modifiers map[string]map[string]string `json:"modifiers,omitempty"`
+}
+
+// OperationManifest describes a registered operation.
+type OperationManifest struct {
+	Name string `json:"name"`
+	// Kinds of the fields the operation accepts, as [reflect.Kind]
+	// names, e.g. "string", "slice". Empty for any.
+	Kinds []string `json:"kinds,omitempty"`
+}
+
+// KindedOperation is an optional interface for an [Operation] that only
+// accepts fields of some kinds. Reported in manifests, see [OperationManifest].
+type KindedOperation interface {
+	Operation
+
+	Kinds() []reflect.Kind
+}
+
+// LoadManifest reads a JSON manifest from the file at path.
+func LoadManifest(path string) (Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer f.Close()
+
+	return ReadManifest(f)
+}
+
+// ReadManifest reads a JSON manifest from r.
+func ReadManifest(r io.Reader) (Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrManifestInvalid, err)
+	}
+	return m, nil
+}
+
+// WriteJSON writes m as indented JSON to w.
+func (m Manifest) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// Manifest describes the flat grammars of the executor's builder and the
+// operations of its registry. Other grammars are left out.
+func (exec *Executor) Manifest() Manifest {
+	var m Manifest
+
+	exec.builder.mu.RLock()
+	for _, grammar := range exec.builder.grammars {
+		if fg, ok := grammar.(*FlatGrammar); ok {
+			m.Grammars = append(m.Grammars, fg.manifest())
+		}
+	}
+	exec.builder.mu.RUnlock()
+
+	slices.SortFunc(m.Grammars, func(a, b GrammarManifest) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	reg := exec.reg.Load()
+	for _, name := range reg.names() {
+		om := OperationManifest{Name: name}
+
+		op, _ := reg.getOperation(name)
+		if ko, ok := op.(KindedOperation); ok {
+			for _, kind := range ko.Kinds() {
+				om.Kinds = append(om.Kinds, kind.String())
+			}
+		}
+
+		m.Operations = append(m.Operations, om)
+	}
+
+	return m
+}
+
+func (fg *FlatGrammar) manifest() GrammarManifest {
+	gm := GrammarManifest{
+		Key:            fg.key,
+		WalkType:       walkTypeNames[fg.walkType],
+		Format:         flatFormatNames[fg.format],
+		Separator:      string(fg.separator),
+		Arity:          grammarArityNames[fg.arity],
+		ModifierFormat: modFormatNames[fg.modformat],
+		DefaultOp:      fg.defaultOp,
+		ImplicitOp:     fg.implicitOp,
+	}
+
+	for modkey, spec := range fg.sharedMods {
+		if gm.SharedModifiers == nil {
+			gm.SharedModifiers = map[string]string{}
+		}
+		gm.SharedModifiers[modkey] = modKindNames[spec.kind]
+	}
+
+	for opkey, opSpec := range fg.opSpecs {
+		if gm.Modifiers == nil {
+			gm.Modifiers = map[string]map[string]string{}
+		}
+		mods := map[string]string{}
+		for modkey, spec := range opSpec.modSpecs {
+			mods[modkey] = modKindNames[spec.kind]
+		}
+		gm.Modifiers[opkey] = mods
+	}
+
+	return gm
+}
+
+// Grammar builds the grammar described by gm.
+//
+// The grammar only splits and parses tags, it has no combiner or
+// transformer, and an [ApplyWalk] grammar's default applier.
+func (gm GrammarManifest) Grammar() (Grammar, error) {
+	format, err := manifestValue(flatFormatNames, "format", gm.Format)
+	if err != nil {
+		return nil, err
+	}
+	arity, err := manifestValue(grammarArityNames, "arity", gm.Arity)
+	if err != nil {
+		return nil, err
+	}
+	modformat, err := manifestValue(modFormatNames, "modifierFormat", gm.ModifierFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := NewGrammarConfig().
+		SetKey(gm.Key).
+		SetWalkType(ApplyWalk).
+		SetArity(arity).
+		SetModifierFormat(modformat).
+		SetDefaultOperation(gm.DefaultOp).
+		SetImplicitOperation(gm.ImplicitOp)
+
+	for modkey, kindName := range gm.SharedModifiers {
+		kind, err := manifestValue(modKindNames, "modifier "+modkey, kindName)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SetSharedModifier(modkey, ModifierUseOperation, kind)
+	}
+
+	for opkey, mods := range gm.Modifiers {
+		for modkey, kindName := range mods {
+			kind, err := manifestValue(modKindNames, "modifier "+modkey, kindName)
+			if err != nil {
+				return nil, err
+			}
+			cfg.SetCustomModifier(opkey, modkey, ModifierUseOperation, kind)
+		}
+	}
+
+	return cfg.SetFlatStructure().
+		SetFormat(format, FlatGrammarSeparator(gm.Separator)).
+		Build()
+}
+
+var (
+	walkTypeNames = map[WalkType]string{
+		CombineWalk:   "combine",
+		ApplyWalk:     "apply",
+		TransformWalk: "transform",
+	}
+	flatFormatNames = map[FlatGrammarFormat]string{
+		FlatFormatDelimited: "delimited",
+		FlatFormatEnclosed:  "enclosed",
+	}
+	grammarArityNames = map[GrammarArity]string{
+		GrammarArityUnary:    "unary",
+		GrammarArityVariadic: "variadic",
+	}
+	modFormatNames = map[ModifierFormat]string{
+		ModFormatKVOnly:  "kv",
+		ModFormatKeyOnly: "key",
+		ModFormatMixed:   "mixed",
+	}
+	modKindNames = map[ModifierKind]string{
+		ModKindBool:      "bool",
+		ModKindInt:       "int",
+		ModKindUInt:      "uint",
+		ModKindFloat:     "float",
+		ModKindComplex:   "complex",
+		ModKindString:    "string",
+		ModKindConverted: "converted",
+	}
+)
+
+// manifestValue returns the value named name, or the zero value if empty.
+func manifestValue[T comparable](names map[T]string, field, name string) (T, error) {
+	var zero T
+	if name == "" {
+		return zero, nil
+	}
+
+	for v, n := range names {
+		if strings.EqualFold(n, name) {
+			return v, nil
+		}
+	}
+
+	return zero, fmt.Errorf("%w: unknown %s %q", ErrManifestInvalid, field, name)
+}
+
+// TagLinter checks struct tags against a [Manifest], without the
+// operations themselves.
+type TagLinter struct {
+	grammars map[string]Grammar
+	ops      map[string]OperationManifest
+	names    []string
+}
+
+// NewTagLinter builds the grammars of m.
+func NewTagLinter(m Manifest) (*TagLinter, error) {
+	tl := &TagLinter{
+		grammars: make(map[string]Grammar, len(m.Grammars)),
+		ops:      make(map[string]OperationManifest, len(m.Operations)),
+	}
+
+	var errs []error
+	for _, gm := range m.Grammars {
+		grammar, err := gm.Grammar()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("grammar %s: %w", gm.Key, err))
+			continue
+		}
+		tl.grammars[gm.Key] = grammar
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	for _, om := range m.Operations {
+		tl.ops[om.Name] = om
+		tl.names = append(tl.names, om.Name)
+	}
+	slices.Sort(tl.names)
+
+	return tl, nil
+}
+
+// Keys returns the sorted tag keys of the linted grammars.
+func (tl *TagLinter) Keys() []string {
+	keys := make([]string, 0, len(tl.grammars))
+	for key := range tl.grammars {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Lint checks tag, the value of the grammar key of the field at path,
+// whose kind is kind:
+//   - malformed tags and modifiers, as the builder would
+//   - unknown operations
+//   - operations not accepting fields of kind
+//
+// Fields tagged `-` are not checked.
+func (tl *TagLinter) Lint(key, path, tag string, kind reflect.Kind) BuildDiagnostics {
+	grammar, ok := tl.grammars[key]
+	if !ok || tag == "-" {
+		return nil
+	}
+
+	lazyOps, err := ParseTag(grammar, tag)
+	if errors.Is(err, ErrEmptyTag) {
+		return nil
+	}
+	if err != nil {
+		return BuildDiagnostics{newDiagnostic(path, tag, err)}
+	}
+
+	var diags BuildDiagnostics
+	for i, lazyOp := range lazyOps {
+		om, ok := tl.ops[namespaced(key, lazyOp.Name)]
+		if !ok {
+			om, ok = tl.ops[lazyOp.Name]
+		}
+		if !ok {
+			d := opDiagnostic(path, tag, lazyOp, fmt.Errorf("operation %s: %w", lazyOp.Name, ErrOpNotFound))
+			d.Suggestion = suggestUnknownOp(grammar, tl.names, lazyOps, i)
+			diags = append(diags, d)
+			continue
+		}
+
+		if len(om.Kinds) > 0 && !slices.Contains(om.Kinds, kind.String()) {
+			d := opDiagnostic(path, tag, lazyOp, fmt.Errorf("operation %s: %w %s", lazyOp.Name, ErrFieldKind, kind))
+			d.Suggestion = fmt.Sprintf("`%s` accepts %s fields", lazyOp.Name, strings.Join(om.Kinds, ", "))
+			diags = append(diags, d)
+		}
+	}
+
+	return diags
+}
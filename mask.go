@@ -0,0 +1,343 @@
+package recipe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaskGrammarKey is the struct tag key read by [NewMaskGrammar].
+//
+// e.g., `mask:"email,density=0.8"`
+const MaskGrammarKey = "mask"
+
+// MaskSep separates the masked fields combined by a [NewMaskGrammar].
+const MaskSep = ", "
+
+// Mask operation names, as used in `mask` tags.
+const (
+	MaskOpEmail  = "email"
+	MaskOpPhone  = "phone"
+	MaskOpCard   = "card"
+	MaskOpIBAN   = "iban"
+	MaskOpRedact = "redact"
+	MaskOpHash   = "hash"
+)
+
+// Mask modifier keys.
+const (
+	// MaskModDensity is the fraction of maskable characters to mask,
+	// between 0 and 1. Defaults to 1.
+	MaskModDensity = "density"
+	// MaskModChar is the character used to mask. Defaults to '*'.
+	MaskModChar = "char"
+	// MaskModKeep is the number of trailing characters [MaskCard]
+	// leaves visible. Defaults to 4.
+	MaskModKeep = "keep"
+	// MaskModWith is the replacement text used by [MaskRedact].
+	// Defaults to "[REDACTED]".
+	MaskModWith = "with"
+	// MaskModLen is the number of hex digits kept by [MaskHash].
+	// Defaults to 8.
+	MaskModLen = "len"
+)
+
+// NewMaskGrammar returns the grammar for `mask` tags.
+//
+// With [CombineWalk], non-empty masked fields are joined into a single
+// string with [MaskSep]. With [TransformWalk], masked values are written
+// back into the walked struct, see [Redact].
+func NewMaskGrammar(wt WalkType) (Grammar, error) {
+	cfg := NewGrammarConfig().
+		SetKey(MaskGrammarKey).
+		SetDescription("Masks and redacts personally identifiable string fields").
+		SetWalkType(wt).
+		SetOpArity(OpUnary).
+		SetArity(GrammarArityUnary).
+		SetModifierFormat(ModFormatKVOnly).
+		SetSharedModifier(MaskModDensity, ModifierUseOperation, ModKindFloat).
+		SetSharedModifier(MaskModChar, ModifierUseOperation, ModKindString).
+		SetDescribedOperation(MaskOpCard, MaskCard{}).
+		SetDescribedOperation(MaskOpRedact, MaskRedact{}).
+		SetDescribedOperation(MaskOpHash, MaskHash{})
+
+	if wt == CombineWalk {
+		cfg = cfg.SetCombiner(StringJoinCombiner{Sep: MaskSep})
+	}
+
+	return cfg.SetFlatStructure().SetFormat(FlatFormatDelimited, InlineSepComma).Build()
+}
+
+// RegisterMaskOperations registers every mask operation under its
+// `mask` tag name.
+//
+// Returns the registration conflicts joined, if any, see
+// [OpRegistry.RegisterOperation].
+func RegisterMaskOperations(reg *OpRegistry) error {
+	return errors.Join(
+		reg.RegisterOperation(MaskOpEmail, MaskEmail{}),
+		reg.RegisterOperation(MaskOpPhone, MaskPhone{}),
+		reg.RegisterOperation(MaskOpCard, MaskCard{}),
+		reg.RegisterOperation(MaskOpIBAN, MaskIBAN{}),
+		reg.RegisterOperation(MaskOpRedact, MaskRedact{}),
+		reg.RegisterOperation(MaskOpHash, MaskHash{}),
+	)
+}
+
+// Redact returns a copy of v with every `mask` tagged field masked.
+//
+// exec must be built from a [NewMaskGrammar] using [TransformWalk].
+// The copy is shallow: only masked fields differ from v.
+func Redact[T any](exec *Executor, v *T) (*T, error) {
+	return RedactWith(exec, nil, v)
+}
+
+// RedactWith is [Redact] under ctx, e.g. to select the mask grammar with
+// [ExecContext.Grammar] when exec has several grammars.
+func RedactWith[T any](exec *Executor, ctx *ExecContext, v *T) (*T, error) {
+	cp := *v
+	err := exec.ExecuteTransformWalk(ctx, []any{&cp})
+	if err != nil {
+		return nil, fmt.Errorf("redacting %T: %w", v, err)
+	}
+	return &cp, nil
+}
+
+// MaskEmail masks the local part of an email address, keeping the domain.
+//
+// e.g., "john.doe@example.com" -> "********@example.com"
+type MaskEmail struct{}
+
+func (MaskEmail) Arity() OpArity { return OpUnary }
+func (MaskEmail) Execute(opts OpOpts, sources ...any) (any, error) {
+	s, mc, err := maskArgs(opts, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	local, domain, ok := strings.Cut(s, "@")
+	if !ok {
+		return mc.mask(s, 0, func(rune) bool { return true }), nil
+	}
+	return mc.mask(local, 0, func(rune) bool { return true }) + "@" + domain, nil
+}
+
+// MaskPhone masks the digits of a phone number except the last two,
+// keeping formatting characters.
+//
+// e.g., "+1 (555) 123-4567" -> "+* (***) ***-**67"
+type MaskPhone struct{}
+
+func (MaskPhone) Arity() OpArity { return OpUnary }
+func (MaskPhone) Execute(opts OpOpts, sources ...any) (any, error) {
+	s, mc, err := maskArgs(opts, sources)
+	if err != nil {
+		return nil, err
+	}
+	return mc.mask(s, 2, unicode.IsDigit), nil
+}
+
+// MaskCard masks a payment card number, keeping the last 4 digits.
+//
+// e.g., "4111 1111 1111 1111" -> "**** **** **** 1111"
+type MaskCard struct{}
+
+func (MaskCard) Arity() OpArity { return OpUnary }
+func (MaskCard) Doc() OperationDoc {
+	return OperationDoc{
+		Description: "Masks a payment card number, keeping its last digits.",
+		Modifiers: []ModifierSpec{
+			NewModifierSpec(MaskModKeep, ModifierUseOperation, ModKindInt).WithDefault("4"),
+		},
+		Input:    reflect.TypeFor[string](),
+		Output:   reflect.TypeFor[string](),
+		Examples: []string{`mask:"card"`, `mask:"card,keep=6"`},
+	}
+}
+func (MaskCard) Execute(opts OpOpts, sources ...any) (any, error) {
+	s, mc, err := maskArgs(opts, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	keep, err := modInt(opts, MaskModKeep, 4)
+	if err != nil {
+		return nil, err
+	}
+	return mc.mask(s, keep, unicode.IsDigit), nil
+}
+
+// MaskIBAN masks an IBAN, keeping the country code and the last 4
+// characters.
+//
+// e.g., "DE89370400440532013000" -> "DE****************3000"
+type MaskIBAN struct{}
+
+func (MaskIBAN) Arity() OpArity { return OpUnary }
+func (MaskIBAN) Execute(opts OpOpts, sources ...any) (any, error) {
+	s, mc, err := maskArgs(opts, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s) <= 2 {
+		return mc.mask(s, 0, isAlnum), nil
+	}
+	return s[:2] + mc.mask(s[2:], 4, isAlnum), nil
+}
+
+// MaskRedact replaces the whole value with a fixed text.
+//
+// e.g., "secret" -> "[REDACTED]"
+type MaskRedact struct{}
+
+func (MaskRedact) Arity() OpArity { return OpUnary }
+func (MaskRedact) Doc() OperationDoc {
+	return OperationDoc{
+		Description: "Replaces a non-empty value with a fixed text.",
+		Modifiers: []ModifierSpec{
+			NewModifierSpec(MaskModWith, ModifierUseOperation, ModKindString).WithDefault("[REDACTED]"),
+		},
+		Input:    reflect.TypeFor[string](),
+		Output:   reflect.TypeFor[string](),
+		Examples: []string{`mask:"redact"`, `mask:"redact,with=***"`},
+	}
+}
+func (MaskRedact) Execute(opts OpOpts, sources ...any) (any, error) {
+	s, _, err := maskArgs(opts, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	if s == "" {
+		return "", nil
+	}
+	return modString(opts, MaskModWith, "[REDACTED]"), nil
+}
+
+// MaskHash replaces the value with a prefix of its SHA-256 hex digest,
+// so equal values stay correlatable without being readable.
+//
+// e.g., "john" -> "96d9632f"
+type MaskHash struct{}
+
+func (MaskHash) Arity() OpArity { return OpUnary }
+func (MaskHash) Doc() OperationDoc {
+	return OperationDoc{
+		Description: "Replaces a non-empty value with a prefix of its SHA-256 hex digest.",
+		Modifiers: []ModifierSpec{
+			NewModifierSpec(MaskModLen, ModifierUseOperation, ModKindInt).WithDefault("8"),
+		},
+		Input:    reflect.TypeFor[string](),
+		Output:   reflect.TypeFor[string](),
+		Examples: []string{`mask:"hash"`, `mask:"hash,len=16"`},
+	}
+}
+func (MaskHash) Execute(opts OpOpts, sources ...any) (any, error) {
+	s, _, err := maskArgs(opts, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	if s == "" {
+		return "", nil
+	}
+
+	n, err := modInt(opts, MaskModLen, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	digest := hex.EncodeToString(sum[:])
+	if n <= 0 || n > len(digest) {
+		n = len(digest)
+	}
+	return digest[:n], nil
+}
+
+var (
+	__ctc__MaskEmail_impl_Operation           Operation          = MaskEmail{}
+	__ctc__MaskPhone_impl_Operation           Operation          = MaskPhone{}
+	__ctc__MaskCard_impl_DescribedOperation   DescribedOperation = MaskCard{}
+	__ctc__MaskIBAN_impl_Operation            Operation          = MaskIBAN{}
+	__ctc__MaskRedact_impl_DescribedOperation DescribedOperation = MaskRedact{}
+	__ctc__MaskHash_impl_DescribedOperation   DescribedOperation = MaskHash{}
+)
+
+// maskConfig holds the shared mask modifiers of an operation.
+type maskConfig struct {
+	density float64
+	char    string
+}
+
+// maskArgs unpacks the single string source and the shared modifiers.
+func maskArgs(opts OpOpts, sources []any) (string, maskConfig, error) {
+	if len(sources) != 1 {
+		return "", maskConfig{}, fmt.Errorf("%w: mask expects 1 source, got %d", ErrOpMismatch, len(sources))
+	}
+
+	s, ok := sources[0].(string)
+	if !ok {
+		return "", maskConfig{}, fmt.Errorf("%w: mask expects string, got %T", ErrOpMismatch, sources[0])
+	}
+
+	density, err := modFloat(opts, MaskModDensity, 1)
+	if err != nil {
+		return "", maskConfig{}, err
+	}
+	if density < 0 || density > 1 {
+		return "", maskConfig{}, fmt.Errorf("%w: %s must be within [0, 1], got %v", ErrModInvalid, MaskModDensity, density)
+	}
+
+	return s, maskConfig{
+		density: density,
+		char:    modString(opts, MaskModChar, "*"),
+	}, nil
+}
+
+// mask replaces maskable runes of s with the mask character.
+//
+// The last keep maskable runes are always left visible. Of the remaining
+// maskable runes, the leading density fraction is masked.
+func (mc maskConfig) mask(s string, keep int, maskable func(rune) bool) string {
+	total := 0
+	for _, r := range s {
+		if maskable(r) {
+			total++
+		}
+	}
+
+	candidates := max(total-keep, 0)
+	n := int(math.Ceil(float64(candidates) * mc.density))
+
+	var sb strings.Builder
+	sb.Grow(len(s) + n*(len(mc.char)-1))
+
+	seen := 0
+	for _, r := range s {
+		if !maskable(r) {
+			sb.WriteRune(r)
+			continue
+		}
+
+		if seen < n {
+			sb.WriteString(mc.char)
+		} else {
+			sb.WriteRune(r)
+		}
+		seen++
+	}
+
+	return sb.String()
+}
+
+func isAlnum(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
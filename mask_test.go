@@ -0,0 +1,119 @@
+package recipe
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMaskOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		op   Operation
+		opts OpOpts
+		in   string
+		want string
+	}{
+		{"email", MaskEmail{}, nil, "john.doe@example.com", "********@example.com"},
+		{"email without domain", MaskEmail{}, nil, "john", "****"},
+		{"email half density", MaskEmail{}, Modifiers{MaskModDensity: "0.5"}, "john@x.io", "**hn@x.io"},
+		{"phone", MaskPhone{}, nil, "+1 (555) 123-4567", "+* (***) ***-**67"},
+		{"card", MaskCard{}, nil, "4111 1111 1111 1111", "**** **** **** 1111"},
+		{"card keep", MaskCard{}, Modifiers{MaskModKeep: "6"}, "4111111111111111", "**********111111"},
+		{"card char", MaskCard{}, Modifiers{MaskModChar: "#"}, "4111-1111", "####-1111"},
+		{"iban", MaskIBAN{}, nil, "DE89370400440532013000", "DE****************3000"},
+		{"iban short", MaskIBAN{}, nil, "DE", "**"},
+		{"redact", MaskRedact{}, nil, "secret", "[REDACTED]"},
+		{"redact with", MaskRedact{}, Modifiers{MaskModWith: "***"}, "secret", "***"},
+		{"redact empty", MaskRedact{}, nil, "", ""},
+		{"hash", MaskHash{}, nil, "john", "96d9632f"},
+		{"hash len", MaskHash{}, Modifiers{MaskModLen: "4"}, "john", "96d9"},
+		{"hash empty", MaskHash{}, nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op.Execute(tt.opts, tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("masked %q to %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskOperationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    OpOpts
+		sources []any
+		want    error
+	}{
+		{"not a string", nil, []any{42}, ErrOpMismatch},
+		{"no source", nil, nil, ErrOpMismatch},
+		{"density out of range", Modifiers{MaskModDensity: "1.5"}, []any{"x"}, ErrModInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MaskEmail{}.Execute(tt.opts, tt.sources...)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+type maskedUser struct {
+	Name  string `mask:"redact"`
+	Email string `mask:"email"`
+	Card  string `mask:"card,keep=2"`
+	Note  string
+	Hash  string `mask:"hash,len=4"`
+}
+
+func newMaskExecutor(t *testing.T, wt WalkType) *Executor {
+	t.Helper()
+
+	grammar, err := NewMaskGrammar(wt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := NewOpRegistry()
+	if err := RegisterMaskOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	return NewExecutor(reg, NewBuilder(grammar))
+}
+
+func TestMaskCombineWalk(t *testing.T) {
+	exec := newMaskExecutor(t, CombineWalk)
+
+	u := maskedUser{Name: "Ada", Email: "ada@example.com", Card: "4111", Note: "kept", Hash: ""}
+	got, err := exec.ExecuteCombineWalk(nil, []any{&u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[REDACTED], ***@example.com, **11"; got != want {
+		t.Errorf("combined %q, want %q", got, want)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	exec := newMaskExecutor(t, TransformWalk)
+
+	u := &maskedUser{Name: "Ada", Email: "ada@example.com", Card: "4111", Note: "kept", Hash: "john"}
+	got, err := Redact(exec, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := maskedUser{Name: "[REDACTED]", Email: "***@example.com", Card: "**11", Note: "kept", Hash: "96d9"}
+	if *got != want {
+		t.Errorf("redacted %+v, want %+v", *got, want)
+	}
+	if u.Name != "Ada" || u.Email != "ada@example.com" {
+		t.Errorf("Redact modified its argument: %+v", *u)
+	}
+}
@@ -0,0 +1,237 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// MergeGrammarKey is the struct tag key read by [NewMergeGrammar].
+//
+// e.g., `merge:"keep"`, `merge:"append"`, `merge:"-"`
+const MergeGrammarKey = "merge"
+
+// Merge policy operation names, as used in `merge` tags.
+//
+// Layers are ordered from lowest to highest precedence: the destination,
+// then every source passed to [Merge].
+const (
+	// MergeOpKeep keeps the first non-zero layer.
+	MergeOpKeep = "keep"
+	// MergeOpOverride takes the last layer, even if zero.
+	MergeOpOverride = "override"
+	// MergeOpOverrideNonZero takes the last non-zero layer.
+	// Applied to untagged fields.
+	MergeOpOverrideNonZero = "override_nonzero"
+	// MergeOpAppend concatenates slices of every layer.
+	MergeOpAppend = "append"
+	// MergeOpDeep merges maps of every layer key by key, recursing
+	// into nested maps. Later layers win on conflicting keys.
+	MergeOpDeep = "deep"
+)
+
+// NewMergeGrammar returns the variadic [ApplyWalk] grammar for `merge` tags.
+//
+// Every exported field is merged with [MergeOpOverrideNonZero] unless
+// tagged otherwise, fields tagged `-` are left untouched. Nested structs
+// are merged field by field, unless their field is tagged.
+func NewMergeGrammar() (Grammar, error) {
+	return NewGrammarConfig().
+		SetKey(MergeGrammarKey).
+		SetDescription("Merges layers of structs of the same type by per-field policy").
+		SetWalkType(ApplyWalk).
+		SetApplier(ReflectSetterApplier{}).
+		SetOpArity(OpVariadic).
+		SetArity(GrammarArityUnary).
+		SetModifierFormat(ModFormatMixed).
+		SetImplicitOperation(MergeOpOverrideNonZero).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+}
+
+// RegisterMergeOperations registers every merge policy under its
+// `merge` tag name.
+//
+// Returns the registration conflicts joined, if any, see
+// [OpRegistry.RegisterOperation].
+func RegisterMergeOperations(reg *OpRegistry) error {
+	return errors.Join(
+		reg.RegisterOperation(MergeOpKeep, MergeKeep{}),
+		reg.RegisterOperation(MergeOpOverride, MergeOverride{}),
+		reg.RegisterOperation(MergeOpOverrideNonZero, MergeOverrideNonZero{}),
+		reg.RegisterOperation(MergeOpAppend, MergeAppend{}),
+		reg.RegisterOperation(MergeOpDeep, MergeDeep{}),
+	)
+}
+
+// Merge merges layers into dst, in increasing order of precedence.
+//
+// exec must be built from a [NewMergeGrammar].
+func Merge[T any](exec *Executor, dst *T, layers ...*T) error {
+	return MergeWith(exec, nil, dst, layers...)
+}
+
+// MergeWith is [Merge] under ctx, e.g. to select the merge grammar with
+// [ExecContext.Grammar] when exec has several grammars.
+func MergeWith[T any](exec *Executor, ctx *ExecContext, dst *T, layers ...*T) error {
+	sources := make([]any, len(layers))
+	for i, l := range layers {
+		sources[i] = l
+	}
+
+	err := exec.ExecuteApplyFieldsWalk(ctx, []any{dst}, sources)
+	if err != nil {
+		return fmt.Errorf("merging %T: %w", dst, err)
+	}
+	return nil
+}
+
+// MergeKeep returns the first non-zero layer.
+type MergeKeep struct{}
+
+func (MergeKeep) Arity() OpArity { return OpVariadic }
+func (MergeKeep) Execute(opts OpOpts, sources ...any) (any, error) {
+	for _, src := range sources {
+		if !isZeroValue(src) {
+			return src, nil
+		}
+	}
+	return nil, nil
+}
+
+// MergeOverride returns the last layer.
+//
+// A nil last layer returns [ZeroResult], so that the field is reset
+// rather than left untouched.
+type MergeOverride struct{}
+
+func (MergeOverride) Arity() OpArity { return OpVariadic }
+func (MergeOverride) Execute(opts OpOpts, sources ...any) (any, error) {
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	if last := sources[len(sources)-1]; last != nil {
+		return last, nil
+	}
+	return ZeroResult, nil
+}
+
+// MergeOverrideNonZero returns the last non-zero layer.
+type MergeOverrideNonZero struct{}
+
+func (MergeOverrideNonZero) Arity() OpArity { return OpVariadic }
+func (MergeOverrideNonZero) Execute(opts OpOpts, sources ...any) (any, error) {
+	for i := len(sources) - 1; i >= 0; i-- {
+		if !isZeroValue(sources[i]) {
+			return sources[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// MergeAppend returns the concatenation of the slices of every layer.
+type MergeAppend struct{}
+
+func (MergeAppend) Arity() OpArity { return OpVariadic }
+func (MergeAppend) Execute(opts OpOpts, sources ...any) (any, error) {
+	var out reflect.Value
+	for _, src := range sources {
+		sv := reflect.ValueOf(src)
+		if !sv.IsValid() {
+			continue
+		}
+		if sv.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("%w: %s expects slices, got %T", ErrOpMismatch, MergeOpAppend, src)
+		}
+		if sv.IsNil() {
+			continue
+		}
+
+		if !out.IsValid() {
+			out = reflect.MakeSlice(sv.Type(), 0, sv.Len())
+		}
+		out = reflect.AppendSlice(out, sv)
+	}
+
+	if !out.IsValid() {
+		return nil, nil
+	}
+	return out.Interface(), nil
+}
+
+// MergeDeep returns a new map holding the entries of every layer.
+type MergeDeep struct{}
+
+func (MergeDeep) Arity() OpArity { return OpVariadic }
+func (MergeDeep) Execute(opts OpOpts, sources ...any) (any, error) {
+	var out reflect.Value
+	for _, src := range sources {
+		sv := reflect.ValueOf(src)
+		if !sv.IsValid() {
+			continue
+		}
+		if sv.Kind() != reflect.Map {
+			return nil, fmt.Errorf("%w: %s expects maps, got %T", ErrOpMismatch, MergeOpDeep, src)
+		}
+		if sv.IsNil() {
+			continue
+		}
+
+		if !out.IsValid() {
+			out = reflect.MakeMapWithSize(sv.Type(), sv.Len())
+		}
+		mergeMaps(out, sv)
+	}
+
+	if !out.IsValid() {
+		return nil, nil
+	}
+	return out.Interface(), nil
+}
+
+var (
+	__ctc__MergeKeep_impl_Operation            Operation = MergeKeep{}
+	__ctc__MergeOverride_impl_Operation        Operation = MergeOverride{}
+	__ctc__MergeOverrideNonZero_impl_Operation Operation = MergeOverrideNonZero{}
+	__ctc__MergeAppend_impl_Operation          Operation = MergeAppend{}
+	__ctc__MergeDeep_impl_Operation            Operation = MergeDeep{}
+)
+
+// mergeMaps copies the entries of src into dst. Entries that are maps in
+// both are merged recursively into a new map, leaving the layers intact.
+func mergeMaps(dst, src reflect.Value) {
+	iter := src.MapRange()
+	for iter.Next() {
+		k, v := iter.Key(), iter.Value()
+
+		cur := dst.MapIndex(k)
+		if cur.IsValid() {
+			cv, nv := unwrapInterface(cur), unwrapInterface(v)
+			if cv.Kind() == reflect.Map && nv.Kind() == reflect.Map && cv.Type() == nv.Type() {
+				merged := reflect.MakeMapWithSize(cv.Type(), cv.Len())
+				mergeMaps(merged, cv)
+				mergeMaps(merged, nv)
+				dst.SetMapIndex(k, merged)
+				continue
+			}
+		}
+
+		dst.SetMapIndex(k, v)
+	}
+}
+
+func unwrapInterface(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return v.Elem()
+	}
+	return v
+}
+
+// isZeroValue reports whether v is nil or the zero value of its type.
+func isZeroValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	return reflect.ValueOf(v).IsZero()
+}
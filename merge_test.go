@@ -0,0 +1,144 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type mergeLimits struct {
+	CPU int
+	Mem int `merge:"keep"`
+}
+
+type mergeConfig struct {
+	Name    string
+	Port    int
+	Debug   bool           `merge:"override"`
+	Tags    []string       `merge:"append"`
+	Labels  map[string]any `merge:"deep"`
+	Limits  mergeLimits
+	Pinned  string `merge:"-"`
+	Timeout *int
+}
+
+func newMergeExecutor(t *testing.T) *Executor {
+	t.Helper()
+
+	grammar, err := NewMergeGrammar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := NewOpRegistry()
+	if err := RegisterMergeOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	return NewExecutor(reg, NewBuilder(grammar))
+}
+
+func TestMerge(t *testing.T) {
+	exec := newMergeExecutor(t)
+
+	timeout := 30
+	dst := mergeConfig{
+		Name:   "base",
+		Port:   80,
+		Debug:  true,
+		Tags:   []string{"a"},
+		Labels: map[string]any{"env": "dev", "team": map[string]any{"name": "core", "size": 3}},
+		Limits: mergeLimits{CPU: 1, Mem: 512},
+		Pinned: "pinned",
+	}
+	layer := mergeConfig{
+		Port:    8080,
+		Tags:    []string{"b"},
+		Labels:  map[string]any{"env": "prod", "team": map[string]any{"size": 5}},
+		Limits:  mergeLimits{CPU: 4, Mem: 1024},
+		Pinned:  "ignored",
+		Timeout: &timeout,
+	}
+
+	if err := Merge(exec, &dst, &layer); err != nil {
+		t.Fatal(err)
+	}
+
+	want := mergeConfig{
+		Name:    "base",
+		Port:    8080,
+		Debug:   false,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]any{"env": "prod", "team": map[string]any{"name": "core", "size": 5}},
+		Limits:  mergeLimits{CPU: 4, Mem: 512},
+		Pinned:  "pinned",
+		Timeout: &timeout,
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("merged %+v, want %+v", dst, want)
+	}
+}
+
+func TestMergeOperations(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      Operation
+		sources []any
+		want    any
+		err     error
+	}{
+		{"keep", MergeKeep{}, []any{0, 2, 3}, 2, nil},
+		{"keep all zero", MergeKeep{}, []any{0, ""}, nil, nil},
+		{"override", MergeOverride{}, []any{1, 0}, 0, nil},
+		{"override nil", MergeOverride{}, []any{1, nil}, ZeroResult, nil},
+		{"override nonzero", MergeOverrideNonZero{}, []any{1, 2, 0}, 2, nil},
+		{"append", MergeAppend{}, []any{[]int{1}, []int(nil), []int{2, 3}}, []int{1, 2, 3}, nil},
+		{"append nil", MergeAppend{}, []any{[]int(nil), nil}, nil, nil},
+		{"append string", MergeAppend{}, []any{"a", "b"}, nil, ErrOpMismatch},
+		{"deep", MergeDeep{}, []any{map[string]int{"a": 1}, map[string]int{"a": 2, "b": 3}}, map[string]int{"a": 2, "b": 3}, nil},
+		{"deep string", MergeDeep{}, []any{"a"}, nil, ErrOpMismatch},
+		{"deep int", MergeDeep{}, []any{map[string]int(nil), 1}, nil, ErrOpMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op.Execute(nil, tt.sources...)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeAppendOnString(t *testing.T) {
+	exec := newMergeExecutor(t)
+
+	type tagged struct {
+		Name string `merge:"append"`
+	}
+	dst, layer := tagged{Name: "a"}, tagged{Name: "b"}
+	if err := Merge(exec, &dst, &layer); !errors.Is(err, ErrOpMismatch) {
+		t.Errorf("got %v, want %v", err, ErrOpMismatch)
+	}
+}
+
+func TestMergeOverrideNilLayer(t *testing.T) {
+	exec := newMergeExecutor(t)
+
+	type reset struct {
+		Any    any            `merge:"override"`
+		Ptr    *int           `merge:"override"`
+		Labels map[string]int `merge:"override"`
+		Tags   []string       `merge:"override"`
+	}
+
+	n := 1
+	dst := reset{Any: "a", Ptr: &n, Labels: map[string]int{"a": 1}, Tags: []string{"a"}}
+	if err := Merge(exec, &dst, &reset{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, reset{}) {
+		t.Errorf("merged %+v, want every field reset", dst)
+	}
+}
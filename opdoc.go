@@ -0,0 +1,199 @@
+package recipe
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DescribedOperation is an optional interface for an [Operation] that
+// documents itself and owns the specs of its modifiers.
+//
+// Grammars take its modifiers with [GrammarConfig.SetDescribedOperation],
+// rather than repeating them with SetCustomModifier. Whatever the grammar,
+// its modifiers are checked, and defaulted, when recipes are resolved.
+type DescribedOperation interface {
+	Operation
+
+	Doc() OperationDoc
+}
+
+// OperationDoc documents a [DescribedOperation].
+type OperationDoc struct {
+	// Description is a sentence or short paragraph on what the operation does.
+	Description string
+	// Modifiers are the modifiers the operation reads.
+	Modifiers []ModifierSpec
+
+	// Input is the type of the sources the operation accepts, nil for any.
+	Input reflect.Type
+	// Output is the type of the operation's results, nil if unspecified.
+	Output reflect.Type
+
+	// Examples are struct tags using the operation,
+	// e.g. `mask:"card,keep=4"`
+	Examples []string
+}
+
+// completeModifiers checks mods against specs: required modifiers must be
+// set, and values must parse as their kind. Absent modifiers with a
+// default are set to it.
+func completeModifiers(specs map[string]ModifierSpec, mods Modifiers) error {
+	for _, modkey := range slices.Sorted(maps.Keys(specs)) {
+		spec := specs[modkey]
+
+		val, ok := mods[modkey]
+		switch {
+		case !ok && spec.required:
+			return fmt.Errorf("%w: modifier %s is required", ErrModInvalid, modkey)
+		case !ok && spec.hasDefault:
+			mods[modkey] = spec.def
+		case !ok:
+		case val == "" && spec.kind == ModKindBool:
+			// Key-only
+		default:
+			if err := spec.kind.check(val); err != nil {
+				return fmt.Errorf("%w: modifier %s: %w", ErrModInvalid, modkey, err)
+			}
+		}
+	}
+	return nil
+}
+
+// describedOpts checks the options of a resolved operation against its
+// modifier specs, if described, returning them with defaults set.
+//
+// Only [Modifiers] options are defaulted, others are returned as is.
+func describedOpts(op Operation, opts OpOpts) (OpOpts, error) {
+	dop, ok := op.(DescribedOperation)
+	if !ok {
+		return opts, nil
+	}
+
+	specs := map[string]ModifierSpec{}
+	for _, spec := range dop.Doc().Modifiers {
+		specs[spec.modkey] = spec
+	}
+	if len(specs) == 0 {
+		return opts, nil
+	}
+
+	var mods Modifiers
+	switch o := opts.(type) {
+	case nil:
+		mods = Modifiers{}
+	case Modifiers:
+		mods = maps.Clone(o)
+	default:
+		return opts, nil
+	}
+
+	if err := completeModifiers(specs, mods); err != nil {
+		return nil, err
+	}
+	return mods, nil
+}
+
+// WriteReference writes a Markdown reference page of every registered
+// operation, alias and macro, in name order.
+//
+// Operations that are not a [DescribedOperation] are listed with their
+// arity only.
+func (reg *OpRegistry) WriteReference(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("# Operations\n")
+
+	for _, info := range reg.List() {
+		fmt.Fprintf(&sb, "\n## %s\n\n", info.Name)
+
+		if info.Target != "" {
+			fmt.Fprintf(&sb, "Alias of [%s](#%s).\n", info.Target, anchor(info.Target))
+			continue
+		}
+
+		if m := info.Macro; m != nil {
+			fmt.Fprintf(&sb, "Macro expanding to `%s`.\n", m.Body)
+			if len(m.Params) > 0 {
+				sb.WriteString("\n| Parameter | Default | Required |\n")
+				sb.WriteString("|---|---|---|\n")
+				for _, p := range m.Params {
+					def, required := "", ""
+					if p.Required {
+						required = "yes"
+					} else {
+						def = "`" + p.Default + "`"
+					}
+					fmt.Fprintf(&sb, "| `%s` | %s | %s |\n", p.Name, def, required)
+				}
+			}
+			continue
+		}
+
+		doc := info.Doc
+		if doc.Description != "" {
+			fmt.Fprintf(&sb, "%s\n\n", doc.Description)
+		}
+
+		fmt.Fprintf(&sb, "- Arity: %s\n", arityName(info.Arity))
+		if doc.Input != nil {
+			fmt.Fprintf(&sb, "- Input: `%s`\n", doc.Input)
+		}
+		if doc.Output != nil {
+			fmt.Fprintf(&sb, "- Output: `%s`\n", doc.Output)
+		}
+		if len(info.Kinds) > 0 {
+			kinds := make([]string, len(info.Kinds))
+			for i, kind := range info.Kinds {
+				kinds[i] = "`" + kind.String() + "`"
+			}
+			fmt.Fprintf(&sb, "- Field kinds: %s\n", strings.Join(kinds, ", "))
+		}
+
+		if len(doc.Modifiers) > 0 {
+			sb.WriteString("\n| Modifier | Kind | Use | Default | Required |\n")
+			sb.WriteString("|---|---|---|---|---|\n")
+			for _, spec := range doc.Modifiers {
+				def := ""
+				if v, ok := spec.Default(); ok {
+					def = "`" + v + "`"
+				}
+				required := ""
+				if spec.required {
+					required = "yes"
+				}
+				fmt.Fprintf(&sb, "| `%s` | %s | %s | %s | %s |\n", spec.modkey, spec.kind, spec.use, def, required)
+			}
+		}
+
+		if len(doc.Examples) > 0 {
+			sb.WriteString("\nExamples:\n\n")
+			for _, example := range doc.Examples {
+				fmt.Fprintf(&sb, "    %s\n", example)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// anchor returns the Markdown heading anchor of an operation name.
+func anchor(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), ".", "")
+}
+
+func arityName(arity OpArity) string {
+	switch arity {
+	case OpUnary:
+		return "unary"
+	case OpBinary:
+		return "binary"
+	case OpVariadic:
+		return "variadic"
+	default:
+		return fmt.Sprintf("%d", arity)
+	}
+}
@@ -0,0 +1,125 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// rawOpts are operation options other than [Modifiers].
+type rawOpts struct{}
+
+func (rawOpts) OmitError() bool                { return false }
+func (rawOpts) Modifier(string) (string, bool) { return "", false }
+
+func TestDescribedOpts(t *testing.T) {
+	tests := []struct {
+		name string
+		op   Operation
+		opts OpOpts
+		want OpOpts
+		err  error
+	}{
+		{"defaulted", MaskCard{}, nil, Modifiers{MaskModKeep: "4"}, nil},
+		{"set", MaskCard{}, Modifiers{MaskModKeep: "6"}, Modifiers{MaskModKeep: "6"}, nil},
+		{"not of its kind", MaskCard{}, Modifiers{MaskModKeep: "six"}, nil, ErrModInvalid},
+		{"not described", MaskEmail{}, Modifiers{"x": "1"}, Modifiers{"x": "1"}, nil},
+		{"not modifiers", MaskCard{}, rawOpts{}, rawOpts{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := describedOpts(tt.op, tt.opts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	// Options of the recipe are not modified
+	opts := Modifiers{}
+	if _, err := describedOpts(MaskCard{}, opts); err != nil || len(opts) != 0 {
+		t.Errorf("defaulted the options in place: %v, %v", opts, err)
+	}
+}
+
+func TestCompleteModifiers(t *testing.T) {
+	specs := map[string]ModifierSpec{
+		"n":    NewModifierSpec("n", ModifierUseOperation, ModKindInt).AsRequired(),
+		"flag": NewModifierSpec("flag", ModifierUseOperation, ModKindBool),
+	}
+
+	if err := completeModifiers(specs, Modifiers{"flag": ""}); !errors.Is(err, ErrModInvalid) {
+		t.Errorf("missing required: got %v, want %v", err, ErrModInvalid)
+	}
+	if err := completeModifiers(specs, Modifiers{"n": "1", "flag": ""}); err != nil {
+		t.Errorf("key-only bool: %v", err)
+	}
+	if err := completeModifiers(specs, Modifiers{"n": "1", "flag": "maybe"}); !errors.Is(err, ErrModInvalid) {
+		t.Errorf("invalid bool: got %v, want %v", err, ErrModInvalid)
+	}
+}
+
+func TestDescribedOperationGrammar(t *testing.T) {
+	grammar, err := NewMaskGrammar(CombineWalk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseTag(grammar, "card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LazyOperation{{Name: MaskOpCard, Opts: Modifiers{MaskModKeep: "4"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsed %#v, want %#v", got, want)
+	}
+
+	if _, err := ParseTag(grammar, "card,keep=all"); !errors.Is(err, ErrModInvalid) {
+		t.Errorf("got %v, want %v", err, ErrModInvalid)
+	}
+}
+
+func TestWriteReference(t *testing.T) {
+	reg := NewOpRegistry()
+	if err := RegisterMaskOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Alias("pan", MaskOpCard); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterMacro("secret(with=***)", "redact,with=${with}"); err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := reg.WriteReference(&sb); err != nil {
+		t.Fatal(err)
+	}
+	ref := sb.String()
+
+	for _, want := range []string{
+		"# Operations\n",
+		"## card\n\nMasks a payment card number, keeping its last digits.\n\n- Arity: unary\n- Input: `string`\n- Output: `string`\n",
+		"| `keep` | Int | Operation | `4` |  |\n",
+		"Examples:\n\n    mask:\"card\"\n",
+		// Undocumented operations only have their arity
+		"## email\n\n- Arity: unary\n\n## hash",
+		"## pan\n\nAlias of [card](#card).\n",
+		"## secret\n\nMacro expanding to `redact,with=${with}`.\n",
+		"| `with` | `***` |  |\n",
+	} {
+		if !strings.Contains(ref, want) {
+			t.Errorf("reference lacks %q:\n%s", want, ref)
+		}
+	}
+
+	// Name order
+	if strings.Index(ref, "## card") > strings.Index(ref, "## email") {
+		t.Error("operations not in name order")
+	}
+}
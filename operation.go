@@ -10,8 +10,12 @@ package recipe
 // If on a field, then need to pass source v
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -25,6 +29,8 @@ type OpArity uint8
 const (
 	// OpUnary takes exactly one source argument
 	OpUnary OpArity = iota + 1
+	// OpBinary takes exactly two source arguments
+	OpBinary
 	// OpVariadic takes a variable number of source arguments
 	OpVariadic = 255
 )
@@ -38,6 +44,17 @@ const (
 	AllOrNothing
 )
 
+func (s MultiOpStrategy) String() string {
+	switch s {
+	case FirstSuccess:
+		return "FirstSuccess"
+	case AllOrNothing:
+		return "AllOrNothing"
+	default:
+		return "Unknown"
+	}
+}
+
 type Operation interface {
 	Arity() OpArity
 
@@ -46,6 +63,7 @@ type Operation interface {
 	// sources must match the arity of the operation.
 	//
 	// Returns the result of the operation, or an error if execution failed.
+	// The sources slice is reused by the executor, and must not be retained.
 	//
 	// WARNING: You MUST unpack the sources if a slice when passing to Execute,
 	// e.g., op.Execute(opts, sources...) NOT op.Execute(opts, sources)
@@ -54,6 +72,82 @@ type Operation interface {
 
 type OpOpts interface {
 	OmitError() bool
+
+	// Modifier returns the raw value of the modifier with the given key,
+	// and whether it was present. Key-only modifiers have an empty value.
+	Modifier(key string) (string, bool)
+}
+
+// ModOmitError is the shared modifier key reported by [Modifiers.OmitError].
+const ModOmitError = "omiterror"
+
+// Modifiers is the [OpOpts] produced by the builtin grammars.
+//
+// Maps modifier keys to their raw, unquoted values.
+type Modifiers map[string]string
+
+func (m Modifiers) OmitError() bool {
+	v, ok := m[ModOmitError]
+	if !ok {
+		return false
+	}
+	b, err := parseModBool(v)
+	return err == nil && b
+}
+
+func (m Modifiers) Modifier(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// modString returns the modifier value for key, or def if absent.
+func modString(opts OpOpts, key string, def string) string {
+	if opts == nil {
+		return def
+	}
+	if v, ok := opts.Modifier(key); ok {
+		return v
+	}
+	return def
+}
+
+// modInt returns the modifier value for key parsed as an int, or def if absent.
+func modInt(opts OpOpts, key string, def int) (int, error) {
+	v := modString(opts, key, "")
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%w: modifier %s: %w", ErrModInvalid, key, err)
+	}
+	return i, nil
+}
+
+// modFloat returns the modifier value for key parsed as a float, or def if absent.
+func modFloat(opts OpOpts, key string, def float64) (float64, error) {
+	v := modString(opts, key, "")
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: modifier %s: %w", ErrModInvalid, key, err)
+	}
+	return f, nil
+}
+
+// modBool reports whether the modifier for key is present and true.
+func modBool(opts OpOpts, key string) bool {
+	if opts == nil {
+		return false
+	}
+	v, ok := opts.Modifier(key)
+	if !ok {
+		return false
+	}
+	b, err := parseModBool(v)
+	return err == nil && b
 }
 
 // LazyOperation is a reference to an operation with its execution metadata
@@ -73,27 +167,54 @@ type ResolvedOperation struct {
 
 var (
 	ErrOpNotFound = fmt.Errorf("operation not found")
+	ErrOpExists   = fmt.Errorf("operation already registered")
+	ErrAliasCycle = fmt.Errorf("alias cycle")
 )
 
 type OpRegistry struct {
 	mu         sync.RWMutex
 	operations map[string]Operation
+	// aliases maps alias names to the name they stand for.
+	aliases map[string]string
+	macros  map[string]Macro
+	policy  ConflictPolicy
+	// logger receives the warnings of [ConflictWarn], nil for [slog.Default].
+	logger *slog.Logger
+
+	// version changes with every registration, see [OpRegistry.Version].
+	version uint64
+	// frozen registries are snapshots, see [OpRegistry.Snapshot].
+	frozen bool
+	// snap is the snapshot of the current version, once taken.
+	snap atomic.Pointer[OpRegistry]
 }
 
 func NewOpRegistry() *OpRegistry {
 	return &OpRegistry{
 		operations: make(map[string]Operation),
+		aliases:    make(map[string]string),
+		macros:     make(map[string]Macro),
+		version:    registryVersions.Add(1),
 	}
 }
 
-func (reg *OpRegistry) RegisterOperation(name string, op Operation) {
-	reg.mu.Lock()
-	reg.operations[name] = op
-	reg.mu.Unlock()
+// RegisterOperation registers op under name, e.g. "email", or a
+// namespaced name, e.g. "validate.email".
+//
+// Conflicts with a registered name follow the registry's policy, see
+// [OpRegistry.SetConflictPolicy]. Returns [ErrOpExists] on conflict under
+// [ConflictError].
+func (reg *OpRegistry) RegisterOperation(name string, op Operation) error {
+	return reg.Register(name, op)
 }
 
-func (reg *OpRegistry) resolveOperation(lazyOp LazyOperation) (*ResolvedOperation, error) {
-	op, err := reg.getOperation(lazyOp.Name)
+// resolveOperation resolves lazyOp, preferring the operation of the same
+// name in namespace, see [OpRegistry.Namespace].
+func (reg *OpRegistry) resolveOperation(namespace string, lazyOp LazyOperation) (*ResolvedOperation, error) {
+	op, err := reg.getOperation(namespaced(namespace, lazyOp.Name))
+	if errors.Is(err, ErrOpNotFound) {
+		op, err = reg.getOperation(lazyOp.Name)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -104,10 +225,12 @@ func (reg *OpRegistry) resolveOperation(lazyOp LazyOperation) (*ResolvedOperatio
 	}, nil
 }
 
+// getOperation returns the operation registered under name, following aliases.
 func (reg *OpRegistry) getOperation(name string) (Operation, error) {
 	reg.mu.RLock()
 	defer reg.mu.RUnlock()
-	op, ok := reg.operations[name]
+
+	op, ok := reg.operations[reg.target(name)]
 	if !ok {
 		return nil, ErrOpNotFound
 	}
@@ -0,0 +1,259 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ContextOperation is an optional interface for an [Operation] that can be
+// cancelled, such as one calling a remote service.
+//
+// Walks with an [ExecContext.Context] or a parallelism call ExecuteContext
+// instead of Execute.
+type ContextOperation interface {
+	Operation
+
+	ExecuteContext(ctx context.Context, opts OpOpts, sources ...any) (any, error)
+}
+
+// SetParallelism sets the default number of fields whose operations run
+// concurrently in combine and apply walks. Zero or one runs them one
+// after another. Overridden by [ExecContext.Parallelism].
+//
+// Results are combined, or applied, in field order whatever the
+// parallelism, so they match a sequential walk. Only fields run
+// concurrently: the operations of a field still run in order.
+//
+// Must not be called concurrently with executions.
+func (exec *Executor) SetParallelism(n int) {
+	exec.parallelism = max(n, 0)
+}
+
+// concurrency returns the context and number of workers of a walk, and
+// whether the walk runs on workers at all: with a parallelism over one,
+// or a context to honour.
+func (exec *Executor) concurrency(ctx *ExecContext) (context.Context, int, bool) {
+	workers := exec.parallelism
+	var goCtx context.Context
+	if ctx != nil {
+		if ctx.Parallelism > 0 {
+			workers = ctx.Parallelism
+		}
+		goCtx = ctx.Context
+	}
+
+	if workers <= 1 && goCtx == nil {
+		return nil, 0, false
+	}
+	if goCtx == nil {
+		goCtx = context.Background()
+	}
+	return goCtx, max(workers, 1), true
+}
+
+// parallelFor calls fn for each index below n, on at most workers
+// goroutines, in increasing order of start.
+//
+// The first failure cancels the other calls. Returns the error of the
+// lowest failed index, ignoring calls failing from that cancellation, or
+// the cause of ctx if cancelled before every call started.
+func parallelFor(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	parent := ctx
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		next   atomic.Int64
+		failed atomic.Bool
+		wg     sync.WaitGroup
+		errs   = make([]error, n)
+	)
+	for range min(workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				if err := fn(ctx, i); err != nil {
+					errs[i] = err
+					if failed.CompareAndSwap(false, true) {
+						cancel(err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failed.Load() {
+		for _, err := range errs {
+			if err != nil && (parent.Err() != nil || !errors.Is(err, context.Canceled)) {
+				return err
+			}
+		}
+		return context.Cause(ctx)
+	}
+	if next.Load() < int64(n) {
+		return context.Cause(parent)
+	}
+	return nil
+}
+
+// executeContext executes operation on sources, with ctx if the
+// operation accepts one.
+func executeContext(ctx context.Context, operation *ResolvedOperation, sources []any) (any, error) {
+	if co, ok := operation.Op.(ContextOperation); ok {
+		return co.ExecuteContext(ctx, operation.Opts, sources...)
+	}
+	return operation.Op.Execute(operation.Opts, sources...)
+}
+
+// runParallel executes the plan on walked with workers running leaves,
+// then combines their results in field order.
+func (p *combinePlan) runParallel(ctx context.Context, workers int, combiner Combiner, walked []any) (any, error) {
+	bases := make([]unsafe.Pointer, len(walked))
+	for i, w := range walked {
+		bases[i] = walkedPointer(w)
+	}
+
+	fc, isFieldCombiner := combiner.(FieldCombiner)
+	results := make([]any, len(p.steps))
+
+	err := parallelFor(ctx, len(p.leaves), workers, func(ctx context.Context, i int) error {
+		step := &p.steps[p.leaves[i]]
+		sources := make([]any, len(bases))
+
+		res, err := p.leaf(ctx, combiner, fc, isFieldCombiner, step, bases, sources)
+		if err != nil {
+			return planError(step.path, err)
+		}
+		results[p.leaves[i]] = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sc, isShortCircuit := combiner.(ShortCircuitCombiner)
+
+	accs := []any{combiner.Zero()}
+	for i := 0; i < len(p.steps); i++ {
+		top := len(accs) - 1
+
+		switch p.steps[i].kind {
+		case planEnter:
+			accs = append(accs, combiner.Zero())
+			continue
+		case planExit:
+			accs[top-1] = combiner.Combine(accs[top-1], accs[top])
+			accs = accs[:top]
+		case planLeaf:
+			accs[top] = combiner.Combine(accs[top], results[i])
+		}
+
+		if isShortCircuit && sc.Done(accs[len(accs)-1]) {
+			i = p.steps[i].end - 1
+		}
+	}
+
+	return accs[0], nil
+}
+
+// applyJob is a leaf of an apply walk, with the sources derived by the
+// operations of its parent structs.
+type applyJob struct {
+	eTree   *ExecTree
+	wPtrs   []unsafe.Pointer
+	vals    []any
+	path    string
+	results []any
+}
+
+// walkApplierParallel is walkApplier with workers running the operations
+// of leaves. Results are applied in field order once all succeeded.
+func (exec *Executor) walkApplierParallel(ctx context.Context, workers int, applier Applier, root *ExecTree, wPtrs []unsafe.Pointer, vals []any) error {
+	var jobs []applyJob
+	err := exec.collectApplyJobs(ctx, root, wPtrs, vals, "", &jobs)
+	if err != nil {
+		return err
+	}
+
+	err = parallelFor(ctx, len(jobs), workers, func(ctx context.Context, i int) error {
+		job := &jobs[i]
+		for j := range job.eTree.Operations {
+			operation := &job.eTree.Operations[j]
+
+			res, err := executeContext(ctx, operation, job.vals)
+			if err != nil {
+				return planError(job.path, fmt.Errorf("executing operation %s: %w", operation.Name, err))
+			}
+			job.results = append(job.results, res)
+
+			switch job.eTree.OpStrategy {
+			case FirstSuccess:
+				return nil
+			case AllOrNothing:
+				continue
+			default:
+				return fmt.Errorf("unknown multi-op strategy %d", job.eTree.OpStrategy)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		for _, res := range job.results {
+			err := exec.applyResult(applier, job.eTree, job.wPtrs, res, job.path, nil)
+			if err != nil {
+				return planError(job.path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// collectApplyJobs appends the leaves of eTree with operations to jobs,
+// running the operations of struct nodes to derive their sources.
+func (exec *Executor) collectApplyJobs(ctx context.Context, eTree *ExecTree, wPtrs []unsafe.Pointer, vals []any, path string, jobs *[]applyJob) error {
+	if !eTree.hasChild() {
+		if eTree.hasOperation() {
+			*jobs = append(*jobs, applyJob{eTree: eTree, wPtrs: wPtrs, vals: vals, path: path})
+		}
+		return nil
+	}
+
+	for i := range eTree.Operations {
+		operation := &eTree.Operations[i]
+		res, err := executeContext(ctx, operation, vals)
+		if err != nil {
+			err = fmt.Errorf("executing operation %s on struct %s: %w", operation.Name, eTree.Name, err)
+			if path != "" {
+				err = planError(path, err)
+			}
+			return err
+		}
+		if res != nil {
+			vals = []any{res}
+		}
+	}
+
+	for _, cTree := range eTree.Children {
+		cPtrs := exec.extractChildPointers(cTree, wPtrs)
+		err := exec.collectApplyJobs(ctx, cTree, cPtrs, vals, joinPath(path, cTree.Name), jobs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelFor(t *testing.T) {
+	for _, workers := range []int{1, 3, 16} {
+		var calls [10]atomic.Int64
+		err := parallelFor(context.Background(), len(calls), workers, func(_ context.Context, i int) error {
+			calls[i].Add(1)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range calls {
+			if n := calls[i].Load(); n != 1 {
+				t.Errorf("%d workers: index %d called %d times", workers, i, n)
+			}
+		}
+	}
+}
+
+func TestParallelForErrors(t *testing.T) {
+	errLow, errHigh := errors.New("low"), errors.New("high")
+
+	t.Run("lowest failed index", func(t *testing.T) {
+		// Every call starts before any fails
+		var started sync.WaitGroup
+		started.Add(3)
+
+		err := parallelFor(context.Background(), 3, 3, func(ctx context.Context, i int) error {
+			started.Done()
+			started.Wait()
+
+			switch i {
+			case 0:
+				// Fails from the cancellation, ignored
+				<-ctx.Done()
+				return ctx.Err()
+			case 1:
+				<-ctx.Done()
+				return errLow
+			default:
+				return errHigh
+			}
+		})
+		if err != errLow {
+			t.Errorf("got %v, want %v", err, errLow)
+		}
+	})
+
+	t.Run("cancelled before start", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := parallelFor(ctx, 3, 2, func(context.Context, int) error {
+			t.Error("called after cancellation")
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("cancelled between calls", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cause := errors.New("shutdown")
+
+		var calls atomic.Int64
+		err := parallelFor(ctx, 3, 1, func(context.Context, int) error {
+			calls.Add(1)
+			cancel(cause)
+			return nil
+		})
+		if err != cause {
+			t.Errorf("got %v, want %v", err, cause)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("called %d times, want 1", calls.Load())
+		}
+	})
+
+	t.Run("failed from parent cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		err := parallelFor(ctx, 3, 1, func(ctx context.Context, i int) error {
+			cancel()
+			return ctx.Err()
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+type parallelFields struct {
+	A, B, C, D, E, F string `par:"slow"`
+}
+
+// slowOp returns its source after a delay decreasing with its length,
+// so that later fields finish first.
+type slowOp struct {
+	calls *atomic.Int64
+}
+
+func (slowOp) Arity() OpArity { return OpUnary }
+func (op slowOp) Execute(opts OpOpts, sources ...any) (any, error) {
+	return op.ExecuteContext(context.Background(), opts, sources...)
+}
+func (op slowOp) ExecuteContext(ctx context.Context, _ OpOpts, sources ...any) (any, error) {
+	op.calls.Add(1)
+	s := sources[0].(string)
+	if s == "block" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	select {
+	case <-time.After(time.Duration(8-len(s)) * time.Millisecond):
+		return s, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+var __ctc__slowOp_impl_ContextOperation ContextOperation = slowOp{}
+
+func newParallelExecutor(t *testing.T, calls *atomic.Int64) *Executor {
+	t.Helper()
+
+	grammar, err := NewGrammarConfig().
+		SetKey("par").
+		SetWalkType(CombineWalk).
+		SetCombiner(StringJoinCombiner{Sep: ","}).
+		SetOpArity(OpUnary).
+		SetArity(GrammarArityVariadic).
+		SetModifierFormat(ModFormatMixed).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := NewOpRegistry()
+	reg.RegisterOperation("slow", slowOp{calls: calls})
+	return NewExecutor(reg, NewBuilder(grammar))
+}
+
+func TestParallelCombineOrder(t *testing.T) {
+	var calls atomic.Int64
+	exec := newParallelExecutor(t, &calls)
+	v := parallelFields{A: "a", B: "bb", C: "ccc", D: "dddd", E: "eeeee", F: "ffffff"}
+
+	want, err := exec.ExecuteCombineWalk(nil, []any{&v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want != "a,bb,ccc,dddd,eeeee,ffffff" {
+		t.Fatalf("sequential walk combined %q", want)
+	}
+
+	for _, parallelism := range []int{2, 6} {
+		got, err := exec.ExecuteCombineWalk(&ExecContext{Parallelism: parallelism}, []any{&v})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("parallelism %d combined %q, want %q", parallelism, got, want)
+		}
+	}
+}
+
+func TestParallelCombineCancel(t *testing.T) {
+	var calls atomic.Int64
+	exec := newParallelExecutor(t, &calls)
+	v := parallelFields{A: "a", B: "block", C: "c", D: "d", E: "e", F: "f"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := exec.ExecuteCombineWalk(&ExecContext{Context: ctx, Parallelism: 2}, []any{&v})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,322 @@
+package recipe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// combinePlan is the combine walk of a resolved recipe, flattened into
+// steps at resolution so that executing it does not allocate.
+//
+// Struct nodes are inlined in their parent, so every leaf is addressed
+// by its offset from the walked root. The accumulator of each struct
+// level lives in a slot of a pooled scratch, errors are only built on
+// failure.
+type combinePlan struct {
+	steps []planStep
+	// leaves are the indices of the leaf steps.
+	leaves []int
+	// depth is the maximum struct nesting, sizing the accumulator slots.
+	depth   int
+	scratch sync.Pool
+}
+
+type planStepKind uint8
+
+const (
+	// planEnter starts the accumulator of a struct node
+	planEnter planStepKind = iota
+	// planExit combines the accumulator of a struct node into its parent's
+	planExit
+	// planLeaf combines the results of a leaf into the current accumulator
+	planLeaf
+)
+
+type planStep struct {
+	kind planStepKind
+	tree *ExecTree
+	// path is the dotted path of the node from the root.
+	path string
+	// parentOffset is the offset of the parent struct from the root.
+	parentOffset uintptr
+	// exec runs an operation on a single walked root without boxing its
+	// field, nil for kinds without a typed executor.
+	exec leafExec
+	// end is the index of the exit of the level the step combines into,
+	// len(steps) for the root, where a [ShortCircuitCombiner] done with
+	// the level resumes.
+	end int
+}
+
+// planScratch holds the per-execution buffers of a plan.
+type planScratch struct {
+	accs    []any
+	sources []any
+	bases   []unsafe.Pointer
+}
+
+// leafExec runs operation on the field of a leaf of the walked root at
+// base. scratch holds at least one source.
+type leafExec func(base unsafe.Pointer, operation *ResolvedOperation, scratch []any) (any, error)
+
+// compileCombinePlan flattens the exec tree of rcp. Returns nil if rcp is
+// not a combine recipe.
+func compileCombinePlan(rcp *Recipe) *combinePlan {
+	if rcp.WalkType != CombineWalk {
+		return nil
+	}
+
+	plan := &combinePlan{}
+	plan.compile(rcp.Root, "", 0, 0)
+	plan.link()
+
+	depth := plan.depth
+	plan.scratch.New = func() any {
+		return &planScratch{
+			accs:    make([]any, 0, depth+1),
+			sources: make([]any, 1),
+			bases:   make([]unsafe.Pointer, 0, 1),
+		}
+	}
+
+	return plan
+}
+
+// link sets the end of every step, once all are compiled.
+func (p *combinePlan) link() {
+	// Exits pair with the innermost open enter
+	exits := make([]int, len(p.steps))
+	var open []int
+	for i, step := range p.steps {
+		switch step.kind {
+		case planEnter:
+			open = append(open, i)
+		case planExit:
+			exits[open[len(open)-1]] = i
+			open = open[:len(open)-1]
+		}
+	}
+
+	ends := []int{len(p.steps)}
+	for i := range p.steps {
+		step := &p.steps[i]
+		switch step.kind {
+		case planEnter:
+			step.end = ends[len(ends)-1]
+			ends = append(ends, exits[i])
+		case planExit:
+			ends = ends[:len(ends)-1]
+			step.end = ends[len(ends)-1]
+		case planLeaf:
+			step.end = ends[len(ends)-1]
+		}
+	}
+}
+
+// compile appends the steps of the children of eTree, a struct node at
+// offset from the root and depth levels deep.
+func (p *combinePlan) compile(eTree *ExecTree, path string, offset uintptr, depth int) {
+	p.depth = max(p.depth, depth)
+
+	for _, cTree := range eTree.Children {
+		cPath := joinPath(path, cTree.Name)
+
+		if cTree.hasChild() {
+			p.steps = append(p.steps, planStep{kind: planEnter, tree: cTree, path: cPath})
+			p.compile(cTree, cPath, offset+cTree.fieldOffset, depth+1)
+			p.steps = append(p.steps, planStep{kind: planExit, tree: cTree, path: cPath})
+			continue
+		}
+
+		p.leaves = append(p.leaves, len(p.steps))
+		p.steps = append(p.steps, planStep{
+			kind:         planLeaf,
+			tree:         cTree,
+			path:         cPath,
+			parentOffset: offset,
+			exec:         compileLeafExec(cTree.fieldKind, offset+cTree.fieldOffset),
+		})
+	}
+}
+
+// run executes the plan on walked, which must be pointers to the recipe
+// type, as the reflective combine walk of the root would.
+func (p *combinePlan) run(combiner Combiner, walked []any) (any, error) {
+	s := p.scratch.Get().(*planScratch)
+	defer p.scratch.Put(s)
+
+	bases := s.bases[:0]
+	for _, w := range walked {
+		bases = append(bases, walkedPointer(w))
+	}
+	if cap(s.sources) < len(walked) {
+		s.sources = make([]any, len(walked))
+	}
+	sources := s.sources[:max(len(walked), 1)]
+	accs := append(s.accs[:0], combiner.Zero())
+
+	defer func() {
+		// Do not retain walked values in the pool
+		clear(bases)
+		clear(sources)
+		clear(accs)
+		s.bases, s.accs = bases[:0], accs[:0]
+	}()
+
+	fc, isFieldCombiner := combiner.(FieldCombiner)
+	sc, isShortCircuit := combiner.(ShortCircuitCombiner)
+
+	for i := 0; i < len(p.steps); i++ {
+		step := &p.steps[i]
+		top := len(accs) - 1
+
+		switch step.kind {
+		case planEnter:
+			accs = append(accs, combiner.Zero())
+			continue
+		case planExit:
+			accs[top-1] = combiner.Combine(accs[top-1], accs[top])
+			accs[top] = nil
+			accs = accs[:top]
+		case planLeaf:
+			res, err := p.leaf(nil, combiner, fc, isFieldCombiner, step, bases, sources)
+			if err != nil {
+				return nil, planError(step.path, err)
+			}
+			accs[top] = combiner.Combine(accs[top], res)
+		}
+
+		// Resume at the exit of the level, which checks its parent
+		if isShortCircuit && sc.Done(accs[len(accs)-1]) {
+			i = step.end - 1
+		}
+	}
+
+	return accs[0], nil
+}
+
+// leaf runs the operations of a leaf and combines their results, as
+// walkCombiner does.
+//
+// Operations are passed ctx if not nil, see [ContextOperation].
+func (p *combinePlan) leaf(ctx context.Context, combiner Combiner, fc FieldCombiner, isFieldCombiner bool, step *planStep, bases []unsafe.Pointer, sources []any) (any, error) {
+	eTree := step.tree
+	acc := combiner.Zero()
+
+	typed := step.exec != nil && len(bases) == 1 && ctx == nil
+	if !typed {
+		for i, base := range bases {
+			sources[i] = eTree.fieldExtractor(unsafe.Pointer(uintptr(base) + step.parentOffset))
+		}
+	}
+
+	for i := range eTree.Operations {
+		operation := &eTree.Operations[i]
+
+		var (
+			res any
+			err error
+		)
+		switch {
+		case typed:
+			res, err = step.exec(bases[0], operation, sources)
+		case ctx != nil:
+			res, err = executeContext(ctx, operation, sources[:len(bases)])
+		default:
+			res, err = operation.Op.Execute(operation.Opts, sources[:len(bases)]...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("executing operation %s on field %s: %w", operation.Name, eTree.Name, err)
+		}
+
+		switch {
+		case eTree.OpStrategy == FirstSuccess && isFieldCombiner:
+			return fc.CombineField(acc, step.path, res), nil
+		case eTree.OpStrategy == FirstSuccess:
+			return res, nil
+		case eTree.OpStrategy == AllOrNothing && isFieldCombiner:
+			acc = fc.CombineField(acc, step.path, res)
+		case eTree.OpStrategy == AllOrNothing:
+			acc = combiner.Combine(acc, res)
+		default:
+			return nil, fmt.Errorf("unknown multi-op strategy %d", eTree.OpStrategy)
+		}
+
+		if CombineDone(combiner, acc) {
+			return acc, nil
+		}
+	}
+
+	return acc, nil
+}
+
+// planError wraps err, raised by the leaf at path, as the reflective walk
+// wraps it at every struct level.
+func planError(path string, err error) error {
+	names := strings.Split(path, ".")
+	for i := len(names) - 1; i >= 0; i-- {
+		err = fmt.Errorf("executing struct child %s: %w", names[i], err)
+	}
+	return err
+}
+
+// compileLeafExec returns the typed executor of a field of kind at offset
+// from the root, or nil if the kind has none.
+//
+// Operations implementing [TypedOperation] of the field's kind receive
+// it unboxed, others receive it as the field extractor would box it.
+func compileLeafExec(kind reflect.Kind, offset uintptr) leafExec {
+	switch kind {
+	case reflect.Bool:
+		return typedLeafExec[bool](offset)
+	case reflect.Int:
+		return typedLeafExec[int](offset)
+	case reflect.Int8:
+		return typedLeafExec[int8](offset)
+	case reflect.Int16:
+		return typedLeafExec[int16](offset)
+	case reflect.Int32:
+		return typedLeafExec[int32](offset)
+	case reflect.Int64:
+		return typedLeafExec[int64](offset)
+	case reflect.Uint:
+		return typedLeafExec[uint](offset)
+	case reflect.Uint8:
+		return typedLeafExec[uint8](offset)
+	case reflect.Uint16:
+		return typedLeafExec[uint16](offset)
+	case reflect.Uint32:
+		return typedLeafExec[uint32](offset)
+	case reflect.Uint64:
+		return typedLeafExec[uint64](offset)
+	case reflect.Float32:
+		return typedLeafExec[float32](offset)
+	case reflect.Float64:
+		return typedLeafExec[float64](offset)
+	case reflect.String:
+		return typedLeafExec[string](offset)
+	default:
+		return nil
+	}
+}
+
+func typedLeafExec[T any](offset uintptr) leafExec {
+	return func(base unsafe.Pointer, operation *ResolvedOperation, scratch []any) (any, error) {
+		source := *(*T)(unsafe.Pointer(uintptr(base) + offset))
+		if typed, ok := operation.Op.(TypedOperation[T]); ok {
+			return typed.ExecuteTyped(operation.Opts, source)
+		}
+
+		scratch[0] = source
+		return operation.Op.Execute(operation.Opts, scratch[:1]...)
+	}
+}
+
+// walkedPointer returns the pointer held by w, a pointer to struct.
+func walkedPointer(w any) unsafe.Pointer {
+	return unsafe.Pointer(reflect.ValueOf(w).Pointer())
+}
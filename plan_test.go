@@ -0,0 +1,180 @@
+package recipe
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// shortWide is checkedWide, with a generated walk, see combineShortWide.
+type shortWide struct {
+	First  string `check:"nonempty"`
+	Inner  checkedInner
+	Second string `check:"nonempty"`
+	Third  string `check:"nonempty"`
+}
+
+// combineShortWide is the walk recipegen generates for shortWide.
+func combineShortWide(fields []BoundField, c Combiner, walked any) (any, error) {
+	w := walked.(*shortWide)
+	var (
+		res any
+		err error
+	)
+
+	acc0 := c.Zero()
+	// First
+	res, err = CombineLeaf(c, &fields[0], w.First)
+	if err != nil {
+		return nil, err
+	}
+	acc0 = c.Combine(acc0, res)
+	if CombineDone(c, acc0) {
+		return acc0, nil
+	}
+	// Inner
+	{
+		acc1 := c.Zero()
+		// Inner.A
+		res, err = CombineLeaf(c, &fields[1], w.Inner.A)
+		if err != nil {
+			return nil, err
+		}
+		acc1 = c.Combine(acc1, res)
+		if CombineDone(c, acc1) {
+			goto done1
+		}
+		// Inner.B
+		res, err = CombineLeaf(c, &fields[2], w.Inner.B)
+		if err != nil {
+			return nil, err
+		}
+		acc1 = c.Combine(acc1, res)
+		if CombineDone(c, acc1) {
+			goto done1
+		}
+	done1:
+		acc0 = c.Combine(acc0, acc1)
+		if CombineDone(c, acc0) {
+			return acc0, nil
+		}
+	}
+	// Second
+	res, err = CombineLeaf(c, &fields[3], w.Second)
+	if err != nil {
+		return nil, err
+	}
+	acc0 = c.Combine(acc0, res)
+	if CombineDone(c, acc0) {
+		return acc0, nil
+	}
+	// Third
+	res, err = CombineLeaf(c, &fields[4], w.Third)
+	if err != nil {
+		return nil, err
+	}
+	acc0 = c.Combine(acc0, res)
+	if CombineDone(c, acc0) {
+		return acc0, nil
+	}
+
+	return acc0, nil
+}
+
+// countingAnd is a [ShortCircuitAndCombiner] counting its combines.
+type countingAnd struct {
+	ShortCircuitAndCombiner
+	combines *atomic.Int64
+}
+
+func (c countingAnd) Combine(acc, result any) any {
+	c.combines.Add(1)
+	return c.ShortCircuitAndCombiner.Combine(acc, result)
+}
+
+func TestShortCircuit(t *testing.T) {
+	var generatedWalks atomic.Int64
+	RegisterGenerated(GeneratedRecipe{
+		Grammar: "check",
+		Type:    reflect.TypeFor[shortWide](),
+		Fields: []GeneratedField{
+			{Path: "First", Ops: []string{"nonempty"}},
+			{Path: "Inner.A", Ops: []string{"nonempty"}},
+			{Path: "Inner.B", Ops: []string{"nonempty"}},
+			{Path: "Second", Ops: []string{"nonempty"}},
+			{Path: "Third", Ops: []string{"nonempty"}},
+		},
+		Combine: func(fields []BoundField, c Combiner, walked any) (any, error) {
+			generatedWalks.Add(1)
+			return combineShortWide(fields, c, walked)
+		},
+	})
+
+	tests := []struct {
+		name string
+		v    shortWide
+		want bool
+		// calls of walks executing operations in field order
+		calls int64
+		// combines of every walk, the executor's combine of the root
+		// included. Parallel walks run every operation before combining
+		// their results.
+		combines int64
+	}{
+		// Stops at the root
+		{"first", shortWide{Inner: checkedInner{A: "a", B: "b"}, Second: "x", Third: "y"}, false, 1, 2},
+		// Resumes at the exit of Inner, which stops at the root
+		{"nested", shortWide{First: "a", Inner: checkedInner{B: "b"}, Second: "x", Third: "y"}, false, 2, 4},
+		{"last", shortWide{First: "a", Inner: checkedInner{A: "a", B: "b"}, Second: "x"}, false, 5, 7},
+		{"none", shortWide{First: "a", Inner: checkedInner{A: "a", B: "b"}, Second: "x", Third: "y"}, true, 5, 7},
+	}
+
+	walks := []struct {
+		name string
+		ctx  *ExecContext
+	}{
+		{"plan", nil},
+		{"generated", nil},
+		{"parallel", &ExecContext{Parallelism: 3}},
+	}
+
+	for _, tt := range tests {
+		for _, walk := range walks {
+			t.Run(tt.name+"/"+walk.name, func(t *testing.T) {
+				var calls, combines atomic.Int64
+				exec := newCheckExecutor(t, countingAnd{combines: &combines}, &calls)
+
+				var got any
+				var err error
+				if walk.name == "plan" {
+					// Of the same shape, without a generated walk
+					v := checkedWide(tt.v)
+					got, err = exec.ExecuteCombineWalk(walk.ctx, []any{&v})
+				} else {
+					got, err = exec.ExecuteCombineWalk(walk.ctx, []any{&tt.v})
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.want {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+
+				wantCalls := tt.calls
+				if walk.name == "parallel" {
+					wantCalls = 5
+				}
+				if calls.Load() != wantCalls {
+					t.Errorf("called %d operations, want %d", calls.Load(), wantCalls)
+				}
+				if combines.Load() != tt.combines {
+					t.Errorf("combined %d times, want %d", combines.Load(), tt.combines)
+				}
+			})
+		}
+	}
+
+	if generatedWalks.Load() != int64(len(tests)) {
+		t.Errorf("generated walk ran %d times, want %d", generatedWalks.Load(), len(tests))
+	}
+}
@@ -1,12 +1,17 @@
 package recipe
 
 import (
+	"context"
 	"reflect"
 	"unsafe"
 )
 
 type Recipe struct {
 	Root *ExecTree
+	// Type is the struct type the recipe was built for.
+	Type reflect.Type
+	// Grammar is the key of the grammar the recipe was built with.
+	Grammar string
 
 	Arity OpArity
 
@@ -21,12 +26,41 @@ type Recipe struct {
 	applier     Applier
 	transformer Transformer
 	resolved    bool
+
+	// generated is the bound generated walk, if any, see [RegisterGenerated].
+	generated *generatedPlan
+	// plan is the flattened combine walk, compiled at resolution.
+	plan *combinePlan
+
+	// registry is the snapshot the recipe was resolved against.
+	registry *OpRegistry
+	// source is the unresolved recipe the recipe was resolved from.
+	source *Recipe
 }
 
 type ExecContext struct {
+	// Grammar selects the recipe of the grammar with this key,
+	// see [Builder.AddGrammar]. Empty for the default grammar.
+	Grammar string
+
 	CombinerOverride    Combiner
 	ApplierOverride     Applier
 	TransformerOverride Transformer
+
+	// Context cancels the walk once done, passed to operations
+	// implementing [ContextOperation].
+	Context context.Context
+	// Parallelism overrides [Executor.SetParallelism] if positive.
+	// Traced walks are never parallel.
+	Parallelism int
+
+	// Explain traces the walk into Trace.
+	//
+	// See: [ExplainMode]
+	Explain ExplainMode
+	// Trace receives the steps of the walk when Explain is set.
+	// Allocated by the executor if nil.
+	Trace *ExecTrace
 }
 
 // ExecTree now supports multiple operations per field
@@ -34,6 +68,8 @@ type ExecTree struct {
 	// Name name in the struct
 	Name string
 
+	tag string // Raw grammar tag of the field, for diagnostics
+
 	fieldIdx    int          // Index in parent struct.Fields
 	fieldOffset uintptr      // Offset in parent struct
 	fieldType   reflect.Type // Type of the field
@@ -52,13 +88,15 @@ type ExecTree struct {
 
 	// Tree structure for nested fields
 	//
-	// Empty if this recipe is not a struct
+	// Empty if this recipe is not a struct. Operations on a struct
+	// node are only executed by [ApplyWalk], where their result
+	// becomes the source of the children.
 	Children []*ExecTree
 
 	// fieldExtractor: pre-compiled field extractor to avoid reflect.NewAt overhead.
 	// Takes a pointer to parent struct, returns field value as any
 	//
-	// Only non-nil if this is a leaf node (no Children)
+	// Nil for the root node
 	fieldExtractor func(structPtr unsafe.Pointer) any
 	// structAddressor: pre-compiled struct extractor to avoid reflect.NewAt overhead.
 	// Takes pointer to parent struct, returns pointer to child struct
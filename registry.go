@@ -0,0 +1,334 @@
+package recipe
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ConflictPolicy decides what registering an already registered name does.
+type ConflictPolicy uint8
+
+const (
+	// ConflictOverwrite replaces the registered operation, alias or macro
+	ConflictOverwrite ConflictPolicy = iota
+	// ConflictError refuses the registration with [ErrOpExists]
+	ConflictError
+	// ConflictWarn replaces the registered operation, alias or macro,
+	// logging a warning through the registry's logger, see
+	// [OpRegistry.SetLogger]
+	ConflictWarn
+)
+
+func (p ConflictPolicy) String() string {
+	switch p {
+	case ConflictOverwrite:
+		return "Overwrite"
+	case ConflictError:
+		return "Error"
+	case ConflictWarn:
+		return "Warn"
+	default:
+		return "Unknown"
+	}
+}
+
+// OpInfo describes a registered operation, alias or macro.
+type OpInfo struct {
+	// Name is the registered name, e.g. "validate.email"
+	Name string
+	// Namespace is the part of Name before its last dot, empty if none.
+	Namespace string
+	// Target is the name an alias stands for, empty if not an alias.
+	Target string
+
+	// Op is the registered operation, or the one the alias resolves to.
+	// Nil for an alias of a name not registered.
+	Op    Operation
+	Arity OpArity
+	// Kinds of the fields the operation accepts, empty for any.
+	//
+	// See: [KindedOperation]
+	Kinds []reflect.Kind
+	// Doc documents the operation, if a [DescribedOperation].
+	Doc OperationDoc
+
+	// Macro is the macro registered under Name, or the one the alias
+	// resolves to. Nil if not a macro.
+	Macro *Macro
+}
+
+// SetConflictPolicy sets what registering an already registered name
+// does. Defaults to [ConflictOverwrite].
+func (reg *OpRegistry) SetConflictPolicy(p ConflictPolicy) {
+	reg.mu.Lock()
+	reg.policy = p
+	reg.mu.Unlock()
+}
+
+// SetLogger sets the logger of [ConflictWarn] warnings. Defaults to
+// [slog.Default] when nil.
+func (reg *OpRegistry) SetLogger(logger *slog.Logger) {
+	reg.mu.Lock()
+	reg.logger = logger
+	reg.mu.Unlock()
+}
+
+// Register registers op under name, see [OpRegistry.RegisterOperation].
+//
+// Recipes already resolved against the registry are resolved again on
+// their next use, see [OpRegistry.Version].
+func (reg *OpRegistry) Register(name string, op Operation) error {
+	if name == "" || op == nil {
+		return fmt.Errorf("%w: registering %q", ErrOpInvalid, name)
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.frozen {
+		return fmt.Errorf("%w: registering %s", ErrRegistryFrozen, name)
+	}
+	if err := reg.conflict(name); err != nil {
+		return err
+	}
+
+	delete(reg.aliases, name)
+	delete(reg.macros, name)
+	reg.operations[name] = op
+	reg.changed()
+	return nil
+}
+
+// Alias registers alias as another name of the operation registered
+// under name, now or later. Conflicts follow the registry's policy.
+func (reg *OpRegistry) Alias(alias, name string) error {
+	if alias == "" || name == "" {
+		return fmt.Errorf("%w: aliasing %q to %q", ErrOpInvalid, alias, name)
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.frozen {
+		return fmt.Errorf("%w: aliasing %s", ErrRegistryFrozen, alias)
+	}
+	if reg.target(name) == alias {
+		return fmt.Errorf("%w: aliasing %q to %q", ErrAliasCycle, alias, name)
+	}
+	if err := reg.conflict(alias); err != nil {
+		return err
+	}
+
+	delete(reg.operations, alias)
+	delete(reg.macros, alias)
+	reg.aliases[alias] = name
+	reg.changed()
+	return nil
+}
+
+// Unregister removes the operation, alias or macro registered under name,
+// and reports whether there was one. Snapshots are left as they are.
+//
+// Aliases of name are kept, and resolve again once name is registered.
+func (reg *OpRegistry) Unregister(name string) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.frozen {
+		return false
+	}
+
+	_, isOp := reg.operations[name]
+	_, isAlias := reg.aliases[name]
+	_, isMacro := reg.macros[name]
+	delete(reg.operations, name)
+	delete(reg.aliases, name)
+	delete(reg.macros, name)
+	if isOp || isAlias || isMacro {
+		reg.changed()
+	}
+	return isOp || isAlias || isMacro
+}
+
+// Lookup describes the operation, alias or macro registered under name.
+func (reg *OpRegistry) Lookup(name string) (OpInfo, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	return reg.info(name)
+}
+
+// List describes every registered operation, alias and macro, sorted by name.
+func (reg *OpRegistry) List() []OpInfo {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	infos := make([]OpInfo, 0, len(reg.operations)+len(reg.aliases)+len(reg.macros))
+	for _, name := range reg.sortedNames() {
+		info, _ := reg.info(name)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// Namespace returns a view of the registry registering names under
+// namespace, e.g. "email" as "validate.email".
+//
+// Tags of a grammar resolve the operations of the namespace named after
+// its key first, e.g. `validate:"email"` resolves "validate.email", then
+// "email". Tags may also name namespaced operations in full.
+func (reg *OpRegistry) Namespace(namespace string) OpNamespace {
+	return OpNamespace{reg: reg, namespace: namespace}
+}
+
+// OpNamespace registers operations under a namespace of an [OpRegistry].
+type OpNamespace struct {
+	reg       *OpRegistry
+	namespace string
+}
+
+// RegisterOperation registers op under the namespaced name, see
+// [OpRegistry.RegisterOperation].
+func (ns OpNamespace) RegisterOperation(name string, op Operation) error {
+	return ns.reg.RegisterOperation(namespaced(ns.namespace, name), op)
+}
+
+// Register registers op under the namespaced name, see [OpRegistry.Register].
+func (ns OpNamespace) Register(name string, op Operation) error {
+	return ns.reg.Register(namespaced(ns.namespace, name), op)
+}
+
+// Alias registers the namespaced alias of the namespaced name, see
+// [OpRegistry.Alias].
+func (ns OpNamespace) Alias(alias, name string) error {
+	return ns.reg.Alias(namespaced(ns.namespace, alias), namespaced(ns.namespace, name))
+}
+
+// namespaced qualifies name with namespace, if any.
+func namespaced(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// conflict applies the conflict policy to registering name.
+//
+// reg.mu must be held for writing.
+func (reg *OpRegistry) conflict(name string) error {
+	_, isOp := reg.operations[name]
+	_, isAlias := reg.aliases[name]
+	_, isMacro := reg.macros[name]
+	if !isOp && !isAlias && !isMacro {
+		return nil
+	}
+
+	switch reg.policy {
+	case ConflictError:
+		return fmt.Errorf("%w: %s", ErrOpExists, name)
+	case ConflictWarn:
+		logger := reg.logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.Warn("recipe: overwriting registered operation", "name", name)
+	}
+	return nil
+}
+
+// target follows the aliases of name to the name of an operation.
+//
+// reg.mu must be held.
+func (reg *OpRegistry) target(name string) string {
+	// Aliases never cycle, bound the walk regardless
+	for range len(reg.aliases) {
+		next, ok := reg.aliases[name]
+		if !ok {
+			break
+		}
+		name = next
+	}
+	return name
+}
+
+// info describes name.
+//
+// reg.mu must be held.
+func (reg *OpRegistry) info(name string) (OpInfo, bool) {
+	info := OpInfo{Name: name, Target: reg.aliases[name]}
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		info.Namespace = name[:i]
+	}
+
+	if m, ok := reg.macros[reg.target(name)]; ok {
+		m.Params = slices.Clone(m.Params)
+		info.Macro = &m
+		return info, true
+	}
+
+	op, isOp := reg.operations[reg.target(name)]
+	if !isOp && info.Target == "" {
+		return OpInfo{}, false
+	}
+
+	if op != nil {
+		info.Op = op
+		info.Arity = op.Arity()
+		if ko, ok := op.(KindedOperation); ok {
+			info.Kinds = ko.Kinds()
+		}
+		if dop, ok := op.(DescribedOperation); ok {
+			info.Doc = dop.Doc()
+		}
+	}
+	return info, true
+}
+
+// names returns the sorted names of the registered operations, aliases
+// and macros.
+func (reg *OpRegistry) names() []string {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	return reg.sortedNames()
+}
+
+// namesIn returns names, with the names of namespace also unqualified,
+// as tags of the grammar of the same key may name them.
+func (reg *OpRegistry) namesIn(namespace string) []string {
+	names := reg.names()
+	if namespace == "" {
+		return names
+	}
+
+	prefix := namespace + "."
+	for _, name := range names {
+		if short, ok := strings.CutPrefix(name, prefix); ok {
+			names = append(names, short)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// sortedNames returns the sorted names of the registered operations,
+// aliases and macros.
+//
+// reg.mu must be held.
+func (reg *OpRegistry) sortedNames() []string {
+	names := make([]string, 0, len(reg.operations)+len(reg.aliases)+len(reg.macros))
+	for name := range reg.operations {
+		names = append(names, name)
+	}
+	for name := range reg.aliases {
+		names = append(names, name)
+	}
+	for name := range reg.macros {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,188 @@
+package recipe
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// caseOp changes the case of a string.
+type caseOp struct{ upper bool }
+
+func (caseOp) Arity() OpArity { return OpUnary }
+func (op caseOp) Execute(_ OpOpts, sources ...any) (any, error) {
+	if op.upper {
+		return strings.ToUpper(sources[0].(string)), nil
+	}
+	return strings.ToLower(sources[0].(string)), nil
+}
+
+var (
+	upperOp Operation = caseOp{upper: true}
+	lowerOp Operation = caseOp{}
+)
+
+func TestRegistryConflictPolicy(t *testing.T) {
+	for _, policy := range []ConflictPolicy{ConflictOverwrite, ConflictWarn} {
+		reg := NewOpRegistry()
+		reg.SetConflictPolicy(policy)
+		reg.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		if err := reg.RegisterOperation("case", upperOp); err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.RegisterOperation("case", lowerOp); err != nil {
+			t.Errorf("%s: %v", policy, err)
+		}
+		if info, _ := reg.Lookup("case"); info.Op != lowerOp {
+			t.Errorf("%s: registered operation not replaced", policy)
+		}
+	}
+
+	reg := NewOpRegistry()
+	reg.SetConflictPolicy(ConflictError)
+	if err := reg.RegisterOperation("case", upperOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterOperation("case", lowerOp); !errors.Is(err, ErrOpExists) {
+		t.Errorf("got %v, want %v", err, ErrOpExists)
+	}
+	if err := reg.Alias("case", "other"); !errors.Is(err, ErrOpExists) {
+		t.Errorf("aliasing: got %v, want %v", err, ErrOpExists)
+	}
+	if info, _ := reg.Lookup("case"); info.Op != upperOp {
+		t.Error("registered operation replaced despite the conflict")
+	}
+
+	// Helpers return the conflicts of their registrations
+	if err := RegisterMaskOperations(reg); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterMaskOperations(reg); !errors.Is(err, ErrOpExists) {
+		t.Errorf("registering twice: got %v, want %v", err, ErrOpExists)
+	}
+}
+
+func TestRegistryConflictLogger(t *testing.T) {
+	var buf bytes.Buffer
+	reg := NewOpRegistry()
+	reg.SetConflictPolicy(ConflictWarn)
+	reg.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	if err := reg.RegisterOperation("case", upperOp); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("warned without a conflict: %s", buf.String())
+	}
+	if err := reg.RegisterOperation("case", lowerOp); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, "level=WARN") || !strings.Contains(got, "name=case") {
+		t.Errorf("logged %q, want a warning naming case", got)
+	}
+}
+
+func TestRegistryInvalid(t *testing.T) {
+	reg := NewOpRegistry()
+	if err := reg.RegisterOperation("", upperOp); !errors.Is(err, ErrOpInvalid) {
+		t.Errorf("empty name: got %v, want %v", err, ErrOpInvalid)
+	}
+	if err := reg.RegisterOperation("nil", nil); !errors.Is(err, ErrOpInvalid) {
+		t.Errorf("nil operation: got %v, want %v", err, ErrOpInvalid)
+	}
+
+	snap := reg.Snapshot()
+	if err := snap.RegisterOperation("late", upperOp); !errors.Is(err, ErrRegistryFrozen) {
+		t.Errorf("snapshot: got %v, want %v", err, ErrRegistryFrozen)
+	}
+}
+
+func TestRegistryAliases(t *testing.T) {
+	reg := NewOpRegistry()
+
+	// Aliases may precede their target
+	if err := reg.Alias("shout", "upper"); err != nil {
+		t.Fatal(err)
+	}
+	if info, ok := reg.Lookup("shout"); !ok || info.Target != "upper" || info.Op != nil {
+		t.Errorf("dangling alias described as %+v, %t", info, ok)
+	}
+
+	if err := reg.RegisterOperation("upper", upperOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Alias("yell", "shout"); err != nil {
+		t.Fatal(err)
+	}
+	op, err := reg.getOperation("yell")
+	if err != nil || op != upperOp {
+		t.Errorf("alias of alias resolved to %v, %v", op, err)
+	}
+
+	if err := reg.Alias("upper", "yell"); !errors.Is(err, ErrAliasCycle) {
+		t.Errorf("got %v, want %v", err, ErrAliasCycle)
+	}
+
+	// Unregistering the target keeps its aliases
+	if !reg.Unregister("upper") {
+		t.Fatal("upper not registered")
+	}
+	if _, err := reg.getOperation("yell"); !errors.Is(err, ErrOpNotFound) {
+		t.Errorf("got %v, want %v", err, ErrOpNotFound)
+	}
+	if reg.Unregister("upper") {
+		t.Error("unregistered twice")
+	}
+
+	var names []string
+	for _, info := range reg.List() {
+		names = append(names, info.Name)
+	}
+	if want := []string{"shout", "yell"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("listed %v, want %v", names, want)
+	}
+}
+
+func TestRegistryNamespace(t *testing.T) {
+	exec := newDefineExecutor(t)
+	reg := exec.reg.Load()
+
+	ns := reg.Namespace("def")
+	if err := ns.RegisterOperation("upper", upperOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := ns.Alias("echo", "upper"); err != nil {
+		t.Fatal(err)
+	}
+
+	info, ok := reg.Lookup("def.echo")
+	if !ok || info.Namespace != "def" || info.Target != "def.upper" {
+		t.Errorf("described as %+v, %t", info, ok)
+	}
+
+	// `def` tags resolve the `def` namespace first
+	got, err := exec.ExecuteCombineWalk(nil, []any{&account{Name: "ada"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"Name": "ADA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Other namespaces are not searched
+	if err := reg.Namespace("other").RegisterOperation("echo", lowerOp); err != nil {
+		t.Fatal(err)
+	}
+	got, err = exec.ExecuteCombineWalk(nil, []any{&account{Name: "Ada"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"Name": "ADA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,402 @@
+package recipe
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSchemaNoType = fmt.Errorf("recipe has no struct type")
+)
+
+// SchemaDialect selects the flavor of schema produced by [GenerateSchema].
+type SchemaDialect uint8
+
+const (
+	// SchemaDraft202012: standalone JSON Schema draft 2020-12 document.
+	SchemaDraft202012 SchemaDialect = iota + 1
+
+	// SchemaOpenAPI31: OpenAPI 3.1 schema object, to be placed under
+	// `components.schemas`. Recursive types reference the components of
+	// their name, so its `$defs` are to be placed there too.
+	SchemaOpenAPI31
+)
+
+func (sd SchemaDialect) String() string {
+	switch sd {
+	case SchemaDraft202012:
+		return "JSON Schema 2020-12"
+	case SchemaOpenAPI31:
+		return "OpenAPI 3.1"
+	default:
+		return "Unknown"
+	}
+}
+
+// JSONSchemaDraft202012 is the `$schema` of [SchemaDraft202012] documents.
+const JSONSchemaDraft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+// Validation operation keys understood by [GenerateSchema].
+//
+// The value of an operation is its modifier of the same key, e.g. `8` for
+// `min=8`.
+const (
+	SchemaOpRequired = "required"
+	SchemaOpMin      = "min"
+	SchemaOpMax      = "max"
+	SchemaOpOneOf    = "oneof" // space separated values, e.g. `oneof=red green`
+	SchemaOpRegex    = "regex"
+)
+
+// SchemaOperation is an optional interface for an [Operation] that
+// contributes keywords to the schema of the field it validates.
+//
+// Keywords are merged into the field schema after the builtin mappings,
+// overriding them.
+type SchemaOperation interface {
+	Operation
+
+	// Schema returns the keywords for a field of type fieldType, given
+	// the operation as written in the tag.
+	Schema(lazyOp LazyOperation, fieldType reflect.Type) map[string]any
+}
+
+// GenerateSchema builds the schema of the struct described by rcp.
+//
+// Every exported field is included, named after its `json` tag when
+// present. Operations of tagged fields map to validation keywords, see
+// [SchemaOpRequired] and related keys, and [SchemaOperation]. The latter
+// are only consulted once rcp has been resolved by an [Executor].
+//
+// Pointer fields are nullable. Recursive types are defined once under
+// `$defs`, and referenced with `$ref`.
+func GenerateSchema(rcp *Recipe, dialect SchemaDialect) (map[string]any, error) {
+	if rcp.Type == nil || rcp.Type.Kind() != reflect.Struct {
+		return nil, ErrSchemaNoType
+	}
+
+	sg := &schemaGenerator{dialect: dialect, refs: map[reflect.Type]string{}, defs: map[string]any{}}
+	schema, err := sg.object(rcp.Root, rcp.Type)
+	if err != nil {
+		return nil, fmt.Errorf("generating %s schema for %s: %w", dialect, rcp.Type.Name(), err)
+	}
+
+	schema["title"] = rcp.Type.Name()
+	if len(sg.defs) > 0 {
+		schema["$defs"] = sg.defs
+	}
+
+	switch dialect {
+	case SchemaDraft202012:
+		schema["$schema"] = JSONSchemaDraft202012
+	case SchemaOpenAPI31:
+	default:
+		return nil, fmt.Errorf("unknown schema dialect %d", dialect)
+	}
+
+	return schema, nil
+}
+
+// Schema resolves the recipe of wet, a struct type, and generates its schema.
+//
+// See: [GenerateSchema]
+func (exec *Executor) Schema(wet reflect.Type, dialect SchemaDialect) (map[string]any, error) {
+	return exec.SchemaWith("", wet, dialect)
+}
+
+// SchemaWith is [Executor.Schema] for the grammar of the given key.
+func (exec *Executor) SchemaWith(key string, wet reflect.Type, dialect SchemaDialect) (map[string]any, error) {
+	rcp, err := exec.resolveRecipeWith(key, wet)
+	if err != nil {
+		return nil, fmt.Errorf("resolving recipe: %w", err)
+	}
+	return GenerateSchema(rcp, dialect)
+}
+
+type schemaGenerator struct {
+	dialect SchemaDialect
+
+	// building are the struct types being built, the root first.
+	building []reflect.Type
+	// refs are the $defs names of the types referenced while being built,
+	// i.e. recursive types.
+	refs map[reflect.Type]string
+	defs map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// object builds the schema of struct type st, whose recipe node is eTree.
+// eTree may be nil for structs without operations.
+//
+// Types referencing themselves, through pointers, slices or maps, are
+// built once into `$defs` and referenced with `$ref`.
+func (sg *schemaGenerator) object(eTree *ExecTree, st reflect.Type) (map[string]any, error) {
+	if slices.Contains(sg.building, st) {
+		return sg.ref(st), nil
+	}
+	sg.building = append(sg.building, st)
+	defer func() { sg.building = sg.building[:len(sg.building)-1] }()
+
+	nodes := map[int]*ExecTree{}
+	if eTree != nil {
+		for _, cTree := range eTree.Children {
+			nodes[cTree.fieldIdx] = cTree
+		}
+	}
+
+	props := map[string]any{}
+	var required []string
+
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+
+		fSchema, isRequired, err := sg.field(nodes[i], field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		props[name] = fSchema
+		if isRequired {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	// The root is referenced as the document itself
+	if name, ok := sg.refs[st]; ok && len(sg.building) > 1 {
+		sg.defs[name] = schema
+		return sg.ref(st), nil
+	}
+
+	return schema, nil
+}
+
+// ref returns the schema referencing st, a struct type being built.
+func (sg *schemaGenerator) ref(st reflect.Type) map[string]any {
+	prefix := "#/$defs/"
+	if sg.dialect == SchemaOpenAPI31 {
+		prefix = "#/components/schemas/"
+	}
+
+	if st == sg.building[0] {
+		if sg.dialect == SchemaOpenAPI31 {
+			return map[string]any{"$ref": prefix + st.Name()}
+		}
+		return map[string]any{"$ref": "#"}
+	}
+
+	name, ok := sg.refs[st]
+	if !ok {
+		name = st.Name()
+		for i := 2; sg.named(name); i++ {
+			name = fmt.Sprintf("%s%d", st.Name(), i)
+		}
+		sg.refs[st] = name
+	}
+	return map[string]any{"$ref": prefix + name}
+}
+
+// named reports whether name is taken by a referenced type.
+func (sg *schemaGenerator) named(name string) bool {
+	for _, n := range sg.refs {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// field builds the schema of a field of type ft, and reports whether
+// it is required.
+func (sg *schemaGenerator) field(eTree *ExecTree, ft reflect.Type) (map[string]any, bool, error) {
+	nullable := false
+	for ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+		nullable = true
+	}
+
+	var schema map[string]any
+	if ft.Kind() == reflect.Struct && ft != timeType {
+		var err error
+		schema, err = sg.object(eTree, ft)
+		if err != nil {
+			return nil, false, err
+		}
+	} else {
+		schema = sg.typeSchema(ft)
+	}
+
+	if nullable {
+		if t, ok := schema["type"].(string); ok {
+			schema["type"] = []string{t, "null"}
+		} else if _, ok := schema["$ref"]; ok {
+			schema = map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+		}
+	}
+
+	if eTree == nil {
+		return schema, false, nil
+	}
+
+	required := false
+	for i, lazyOp := range eTree.LazyOps {
+		key := lazyOp.Name
+		val := modString(lazyOp.Opts, key, "")
+
+		switch key {
+		case SchemaOpRequired:
+			required = true
+		case SchemaOpMin, SchemaOpMax:
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return nil, false, fmt.Errorf("operation %s: %w", lazyOp.Name, err)
+			}
+			schema[boundKeyword(key, ft)] = schemaNumber(n)
+		case SchemaOpOneOf:
+			enum, err := enumValues(strings.Fields(val), ft)
+			if err != nil {
+				return nil, false, fmt.Errorf("operation %s: %w", lazyOp.Name, err)
+			}
+			schema["enum"] = enum
+		case SchemaOpRegex:
+			schema["pattern"] = val
+		}
+
+		if i >= len(eTree.Operations) {
+			continue
+		}
+		if so, ok := eTree.Operations[i].Op.(SchemaOperation); ok {
+			for k, v := range so.Schema(lazyOp, ft) {
+				schema[k] = v
+			}
+		}
+	}
+
+	return schema, required, nil
+}
+
+// typeSchema maps a non-struct Go type to its schema.
+func (sg *schemaGenerator) typeSchema(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if t == durationType {
+		return map[string]any{"type": "integer", "description": "duration in nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		items, _, _ := sg.field(nil, t.Elem())
+		return map[string]any{"type": "array", "items": items}
+	case reflect.Map:
+		values, _, _ := sg.field(nil, t.Elem())
+		return map[string]any{"type": "object", "additionalProperties": values}
+	case reflect.Pointer:
+		s, _, _ := sg.field(nil, t)
+		return s
+	default:
+		// Interfaces and other kinds accept any value
+		return map[string]any{}
+	}
+}
+
+// boundKeyword returns the keyword bounding a field of type t for a
+// `min` or `max` operation.
+func boundKeyword(key string, t reflect.Type) string {
+	lo, hi := "minimum", "maximum"
+	switch t.Kind() {
+	case reflect.String:
+		lo, hi = "minLength", "maxLength"
+	case reflect.Slice, reflect.Array:
+		lo, hi = "minItems", "maxItems"
+	case reflect.Map:
+		lo, hi = "minProperties", "maxProperties"
+	}
+
+	if key == SchemaOpMin {
+		return lo
+	}
+	return hi
+}
+
+// schemaNumber renders whole numbers as integers.
+func schemaNumber(n float64) any {
+	if n == float64(int64(n)) {
+		return int64(n)
+	}
+	return n
+}
+
+// enumValues converts `oneof` values to the JSON type of t.
+func enumValues(vals []string, t reflect.Type) ([]any, error) {
+	enum := make([]any, len(vals))
+	for i, v := range vals {
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, err
+			}
+			enum[i] = schemaNumber(n)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, err
+			}
+			enum[i] = b
+		default:
+			enum[i] = v
+		}
+	}
+	return enum, nil
+}
+
+// jsonName returns the property name of a field, and false if the
+// field is omitted from JSON.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return field.Name, true
+	}
+	return name, true
+}
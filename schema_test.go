@@ -0,0 +1,147 @@
+package recipe
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type schemaAddress struct {
+	City string `json:"city" v:"required"`
+	Zip  string `json:"zip,omitempty" v:"regex='^[0-9]{5}$'"`
+}
+
+type schemaUser struct {
+	Name    string         `json:"name" v:"required,min=3,max=32"`
+	Age     int            `json:"age" v:"min=18"`
+	Color   string         `json:"color" v:"oneof='red green'"`
+	Tags    []string       `json:"tags" v:"max=5"`
+	Nick    *string        `json:"nick"`
+	Address schemaAddress  `json:"address"`
+	Meta    map[string]int `json:"meta"`
+	Secret  string         `json:"-"`
+	hidden  string
+}
+
+type schemaNode struct {
+	Name     string        `json:"name" v:"required"`
+	Next     *schemaNode   `json:"next"`
+	Children []schemaChild `json:"children"`
+}
+
+type schemaChild struct {
+	Parent *schemaNode  `json:"parent"`
+	Self   *schemaChild `json:"self"`
+}
+
+func schemaRecipe(t *testing.T, wt reflect.Type) *Recipe {
+	t.Helper()
+
+	grammar, err := NewGrammarConfig().
+		SetKey("v").
+		SetWalkType(CombineWalk).
+		SetCombiner(BoolAndCombiner{}).
+		SetOpArity(OpUnary).
+		SetArity(GrammarArityVariadic).
+		SetModifierFormat(ModFormatMixed).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rcp, err := NewBuilder(grammar).GetOrBuild(wt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rcp
+}
+
+// normalize round-trips v through JSON, for comparison with literals.
+func normalize(t *testing.T, v any) any {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestGenerateSchema(t *testing.T) {
+	schema, err := GenerateSchema(schemaRecipe(t, reflect.TypeFor[schemaUser]()), SchemaDraft202012)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"$schema":  JSONSchemaDraft202012,
+		"title":    "schemaUser",
+		"type":     "object",
+		"required": []any{"name"},
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string", "minLength": float64(3), "maxLength": float64(32)},
+			"age":   map[string]any{"type": "integer", "minimum": float64(18)},
+			"color": map[string]any{"type": "string", "enum": []any{"red", "green"}},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "maxItems": float64(5)},
+			"nick":  map[string]any{"type": []any{"string", "null"}},
+			"address": map[string]any{
+				"type":     "object",
+				"required": []any{"city"},
+				"properties": map[string]any{
+					"city": map[string]any{"type": "string"},
+					"zip":  map[string]any{"type": "string", "pattern": "^[0-9]{5}$"},
+				},
+			},
+			"meta": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer"}},
+		},
+	}
+	if got := normalize(t, schema); !reflect.DeepEqual(got, want) {
+		t.Errorf("generated\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestGenerateSchemaRecursive(t *testing.T) {
+	rcp := schemaRecipe(t, reflect.TypeFor[schemaNode]())
+
+	tests := []struct {
+		dialect SchemaDialect
+		root    string
+		child   string
+	}{
+		{SchemaDraft202012, "#", "#/$defs/schemaChild"},
+		{SchemaOpenAPI31, "#/components/schemas/schemaNode", "#/components/schemas/schemaChild"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.String(), func(t *testing.T) {
+			schema, err := GenerateSchema(rcp, tt.dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := normalize(t, schema).(map[string]any)
+
+			nullableRef := func(ref string) any {
+				return map[string]any{"anyOf": []any{map[string]any{"$ref": ref}, map[string]any{"type": "null"}}}
+			}
+
+			props := got["properties"].(map[string]any)
+			if !reflect.DeepEqual(props["next"], nullableRef(tt.root)) {
+				t.Errorf("next is %v", props["next"])
+			}
+			if want := map[string]any{"type": "array", "items": map[string]any{"$ref": tt.child}}; !reflect.DeepEqual(props["children"], want) {
+				t.Errorf("children is %v", props["children"])
+			}
+
+			child := got["$defs"].(map[string]any)["schemaChild"].(map[string]any)["properties"].(map[string]any)
+			if !reflect.DeepEqual(child["parent"], nullableRef(tt.root)) || !reflect.DeepEqual(child["self"], nullableRef(tt.child)) {
+				t.Errorf("schemaChild is %v", child)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+var ErrRegistryFrozen = fmt.Errorf("registry snapshot is immutable")
+
+// registryVersions numbers the changes of all registries, so that the
+// versions of different registries never collide.
+var registryVersions atomic.Uint64
+
+// Version returns the version of the registry, which changes with every
+// operation, alias or macro registered or unregistered. Snapshots have
+// the version of the registry they were taken from.
+func (reg *OpRegistry) Version() uint64 {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	return reg.version
+}
+
+// Snapshot returns an immutable copy of the registry, as of its current
+// version. Registering on a snapshot fails with [ErrRegistryFrozen], and
+// a snapshot's snapshot is itself.
+//
+// Executors resolve recipes against snapshots: a recipe resolved against
+// an older version of the registry is resolved again on next use, see
+// [Recipe.Registry] and [Executor.SwapRegistry].
+func (reg *OpRegistry) Snapshot() *OpRegistry {
+	if reg.frozen {
+		return reg
+	}
+	if snap := reg.snap.Load(); snap != nil {
+		return snap
+	}
+
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	snap := &OpRegistry{
+		operations: maps.Clone(reg.operations),
+		aliases:    maps.Clone(reg.aliases),
+		macros:     maps.Clone(reg.macros),
+		policy:     reg.policy,
+		logger:     reg.logger,
+		version:    reg.version,
+		frozen:     true,
+	}
+
+	// Pipelines resolve their steps in the snapshot
+	for name, op := range snap.operations {
+		if p, ok := op.(pipeline); ok {
+			p.reg = snap
+			snap.operations[name] = p
+		}
+	}
+
+	// Concurrent snapshots of the same version are equivalent, keep one
+	if !reg.snap.CompareAndSwap(nil, snap) {
+		if cur := reg.snap.Load(); cur != nil && cur.version == snap.version {
+			return cur
+		}
+	}
+	return snap
+}
+
+// changed versions a change of the registry.
+//
+// reg.mu must be held for writing.
+func (reg *OpRegistry) changed() {
+	reg.version = registryVersions.Add(1)
+	reg.snap.Store(nil)
+}
+
+// Registry returns the registry the executor resolves operations from.
+func (exec *Executor) Registry() *OpRegistry {
+	return exec.reg.Load()
+}
+
+// SwapRegistry atomically replaces the registry the executor resolves
+// operations from, e.g. to reload feature-flagged operations without a
+// restart. reg may be a live registry or a [OpRegistry.Snapshot].
+//
+// Every cached recipe is resolved against reg first. If any fails, the
+// registry is not swapped, and the errors are returned, joined. Walks in
+// progress finish with the recipes they started with.
+func (exec *Executor) SwapRegistry(reg *OpRegistry) error {
+	if reg == nil {
+		return fmt.Errorf("%w: swapping to no registry", ErrOpInvalid)
+	}
+	snap := reg.Snapshot()
+
+	cached := exec.builder.recipes()
+	keys := slices.SortedFunc(maps.Keys(cached), func(a, b recipeKey) int {
+		return strings.Compare(a.grammar+" "+a.wt.String(), b.grammar+" "+b.wt.String())
+	})
+
+	resolved := make(map[recipeKey]*Recipe, len(cached))
+	var errs []error
+	for _, key := range keys {
+		rcp, err := exec.resolveAgainst(cached[key], snap)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolving %s recipe of %s: %w", key.grammar, key.wt, err))
+			continue
+		}
+		resolved[key] = rcp
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	exec.reg.Store(reg)
+	for key, rcp := range resolved {
+		exec.builder.replace(key, cached[key], rcp)
+	}
+	return nil
+}
+
+// Registry returns the registry snapshot the recipe was resolved
+// against, nil if not resolved.
+func (rcp *Recipe) Registry() *OpRegistry {
+	return rcp.registry
+}
+
+// unresolved returns an unresolved copy of the recipe rcp was resolved
+// from, to resolve without mutating rcp, which may be walked concurrently.
+func (rcp *Recipe) unresolved() *Recipe {
+	src := rcp
+	if rcp.source != nil {
+		src = rcp.source
+	}
+
+	c := *src
+	c.Root = cloneTree(src.Root)
+	c.source = src
+	return &c
+}
+
+// cloneTree deep copies the nodes of eTree, without their operations.
+func cloneTree(eTree *ExecTree) *ExecTree {
+	if eTree == nil {
+		return nil
+	}
+
+	c := *eTree
+	c.LazyOps = slices.Clone(eTree.LazyOps)
+	c.Operations = nil
+	c.Children = make([]*ExecTree, len(eTree.Children))
+	for i, child := range eTree.Children {
+		c.Children[i] = cloneTree(child)
+	}
+	return &c
+}
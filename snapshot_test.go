@@ -0,0 +1,158 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	reg := NewOpRegistry()
+	if err := reg.RegisterOperation("case", upperOp); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := reg.Snapshot()
+	if snap.Version() != reg.Version() {
+		t.Errorf("snapshot version %d, registry version %d", snap.Version(), reg.Version())
+	}
+	if reg.Snapshot() != snap || snap.Snapshot() != snap {
+		t.Error("snapshot of an unchanged version taken again")
+	}
+
+	// Snapshots keep the operations of their version
+	version := reg.Version()
+	if err := reg.RegisterOperation("case", lowerOp); err != nil {
+		t.Fatal(err)
+	}
+	if reg.Version() == version {
+		t.Error("registration did not change the version")
+	}
+	if op, _ := snap.getOperation("case"); op != upperOp {
+		t.Error("registration changed the snapshot")
+	}
+	if next := reg.Snapshot(); next == snap || next.Version() != reg.Version() {
+		t.Error("snapshot not taken of the new version")
+	}
+
+	// Versions of different registries never collide
+	if other := NewOpRegistry(); other.Version() == reg.Version() {
+		t.Errorf("registries share version %d", reg.Version())
+	}
+}
+
+func TestSnapshotPipeline(t *testing.T) {
+	reg := NewOpRegistry()
+	if err := reg.RegisterOperation("case", upperOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterPipeline("shout", "case"); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := reg.Snapshot()
+	if err := reg.RegisterOperation("case", lowerOp); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pipelines of a snapshot resolve their steps in the snapshot
+	op, err := snap.getOperation("shout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := op.Execute(nil, "Ada"); err != nil || got != "ADA" {
+		t.Errorf("got %v, %v", got, err)
+	}
+}
+
+func TestSwapRegistry(t *testing.T) {
+	exec := newDefineExecutor(t)
+	v := account{Name: "Ada"}
+
+	got, err := exec.ExecuteCombineWalk(nil, []any{&v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"Name": "Ada"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	rcp, err := exec.resolveRecipe(reflect.TypeFor[account]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rcp.Registry() != exec.Registry().Snapshot() {
+		t.Error("recipe not resolved against the current snapshot")
+	}
+
+	flagged := NewOpRegistry()
+	if err := flagged.RegisterOperation("echo", upperOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.SwapRegistry(flagged.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cached recipes are resolved against the swapped registry
+	got, err = exec.ExecuteCombineWalk(nil, []any{&v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"Name": "ADA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Recipes of walks in progress are not resolved in place
+	if rcp.Registry() == exec.Registry().Snapshot() {
+		t.Error("recipe resolved in place")
+	}
+}
+
+func TestSwapRegistryErrors(t *testing.T) {
+	exec := newDefineExecutor(t)
+	v := account{Name: "Ada"}
+	if _, err := exec.ExecuteCombineWalk(nil, []any{&v}); err != nil {
+		t.Fatal(err)
+	}
+	reg := exec.Registry()
+
+	if err := exec.SwapRegistry(nil); !errors.Is(err, ErrOpInvalid) {
+		t.Errorf("got %v, want %v", err, ErrOpInvalid)
+	}
+
+	// Registries failing to resolve a cached recipe are not swapped
+	err := exec.SwapRegistry(NewOpRegistry())
+	if !errors.Is(err, ErrOpNotFound) {
+		t.Errorf("got %v, want %v", err, ErrOpNotFound)
+	}
+	if exec.Registry() != reg {
+		t.Error("registry swapped despite the failure")
+	}
+
+	got, err := exec.ExecuteCombineWalk(nil, []any{&v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"Name": "Ada"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRegistryChangeResolves(t *testing.T) {
+	exec := newDefineExecutor(t)
+	v := account{Name: "Ada"}
+	if _, err := exec.ExecuteCombineWalk(nil, []any{&v}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recipes resolved against an older version are resolved on next use
+	if err := exec.Registry().RegisterOperation("echo", upperOp); err != nil {
+		t.Fatal(err)
+	}
+	got, err := exec.ExecuteCombineWalk(nil, []any{&v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"Name": "ADA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,246 @@
+package recipe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ExplainMode selects how an [Executor] traces a walk, see [ExecContext].
+type ExplainMode uint8
+
+const (
+	// ExplainOff: no tracing.
+	ExplainOff ExplainMode = iota
+
+	// ExplainRecord: operations are executed as usual, and every step
+	// of the walk is recorded.
+	ExplainRecord
+
+	// ExplainDryRun: operations are recorded with their sources but never
+	// executed. Nothing is combined, applied or transformed.
+	ExplainDryRun
+)
+
+func (em ExplainMode) String() string {
+	switch em {
+	case ExplainOff:
+		return "Off"
+	case ExplainRecord:
+		return "Record"
+	case ExplainDryRun:
+		return "DryRun"
+	default:
+		return "Unknown"
+	}
+}
+
+// TraceStepKind is the kind of a [TraceStep].
+type TraceStepKind uint8
+
+const (
+	// TraceOp: an operation was executed, or would be on a dry run.
+	TraceOp TraceStepKind = iota + 1
+	// TraceCombine: a result was combined into the accumulator.
+	TraceCombine
+	// TraceApply: a result was written to a field.
+	TraceApply
+	// TraceSkip: a field or operation was not executed.
+	TraceSkip
+)
+
+func (k TraceStepKind) String() string {
+	switch k {
+	case TraceOp:
+		return "Op"
+	case TraceCombine:
+		return "Combine"
+	case TraceApply:
+		return "Apply"
+	case TraceSkip:
+		return "Skip"
+	default:
+		return "Unknown"
+	}
+}
+
+// TraceStep is a single recorded step of a walk.
+type TraceStep struct {
+	Kind TraceStepKind
+	// Path is the dotted field path, empty for the root.
+	Path string
+	// Op is the operation name, for [TraceOp] and skipped operations.
+	Op string
+
+	// Sources passed to the operation, for [TraceOp].
+	Sources []any
+	// Result of the operation, or the applied value for [TraceApply].
+	Result any
+	// Err returned by the operation.
+	Err error
+	// Acc is the accumulator after [Combiner.Combine], for [TraceCombine].
+	Acc any
+	// Reason a field or operation was skipped, for [TraceSkip].
+	Reason string
+}
+
+func (step TraceStep) String() string {
+	path := step.Path
+	if path == "" {
+		path = "<root>"
+	}
+
+	switch step.Kind {
+	case TraceOp:
+		if step.Err != nil {
+			return fmt.Sprintf("%s: op %s%v -> error: %v", path, step.Op, step.Sources, step.Err)
+		}
+		return fmt.Sprintf("%s: op %s%v -> %v", path, step.Op, step.Sources, step.Result)
+	case TraceCombine:
+		return fmt.Sprintf("%s: combine -> %v", path, step.Acc)
+	case TraceApply:
+		return fmt.Sprintf("%s: apply %v", path, step.Result)
+	case TraceSkip:
+		if step.Op != "" {
+			return fmt.Sprintf("%s: skip op %s: %s", path, step.Op, step.Reason)
+		}
+		return fmt.Sprintf("%s: skip: %s", path, step.Reason)
+	default:
+		return fmt.Sprintf("%s: unknown step", path)
+	}
+}
+
+// ExecTrace is the ordered record of a traced walk.
+type ExecTrace struct {
+	Mode  ExplainMode
+	Steps []TraceStep
+}
+
+// Field returns the steps recorded for the field at path, in order.
+func (tr *ExecTrace) Field(path string) []TraceStep {
+	var steps []TraceStep
+	for _, step := range tr.Steps {
+		if step.Path == path {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// String renders one step per line.
+func (tr *ExecTrace) String() string {
+	var sb strings.Builder
+	for _, step := range tr.Steps {
+		sb.WriteString(step.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Explain runs a walk of type wt in the given mode and returns its trace.
+//
+// The trace is returned even if the walk fails, up to the failing step.
+func (exec *Executor) Explain(mode ExplainMode, wt WalkType, walked []any, values []any) (*ExecTrace, any, error) {
+	ctx := &ExecContext{Explain: mode}
+	res, err := exec.Execute(ctx, wt, walked, values)
+	return ctx.Trace, res, err
+}
+
+// tracer records the steps of a walk into an [ExecTrace].
+//
+// A nil tracer records nothing, so walkers call it unconditionally.
+type tracer struct {
+	trace *ExecTrace
+	key   string
+}
+
+// newTracer returns the tracer requested by ctx for rcp, or nil.
+func (exec *Executor) newTracer(ctx *ExecContext, rcp *Recipe) *tracer {
+	if ctx == nil || ctx.Explain == ExplainOff {
+		return nil
+	}
+
+	if ctx.Trace == nil {
+		ctx.Trace = &ExecTrace{}
+	}
+	ctx.Trace.Mode = ctx.Explain
+
+	return &tracer{trace: ctx.Trace, key: rcp.Grammar}
+}
+
+func (tr *tracer) dryRun() bool {
+	return tr != nil && tr.trace.Mode == ExplainDryRun
+}
+
+func (tr *tracer) record(step TraceStep) {
+	if tr == nil {
+		return
+	}
+	tr.trace.Steps = append(tr.trace.Steps, step)
+}
+
+func (tr *tracer) op(path, name string, sources []any, res any, err error) {
+	if tr == nil {
+		return
+	}
+	tr.record(TraceStep{
+		Kind:    TraceOp,
+		Path:    path,
+		Op:      name,
+		Sources: append([]any(nil), sources...),
+		Result:  res,
+		Err:     err,
+	})
+}
+
+func (tr *tracer) combine(path string, acc any) {
+	tr.record(TraceStep{Kind: TraceCombine, Path: path, Acc: acc})
+}
+
+func (tr *tracer) apply(path string, value any) {
+	tr.record(TraceStep{Kind: TraceApply, Path: path, Result: value})
+}
+
+func (tr *tracer) skip(path, reason string) {
+	tr.record(TraceStep{Kind: TraceSkip, Path: path, Reason: reason})
+}
+
+// skipOps records the operations of a field that were not executed.
+func (tr *tracer) skipOps(path string, ops []ResolvedOperation, reason string) {
+	if tr == nil {
+		return
+	}
+	for _, operation := range ops {
+		tr.record(TraceStep{Kind: TraceSkip, Path: path, Op: operation.Name, Reason: reason})
+	}
+}
+
+// structNode records the fields of struct type st missing from eTree,
+// and why the builder left them out.
+func (tr *tracer) structNode(eTree *ExecTree, st reflect.Type, path string) {
+	if tr == nil || st == nil || st.Kind() != reflect.Struct {
+		return
+	}
+
+	present := make(map[int]bool, len(eTree.Children))
+	for _, cTree := range eTree.Children {
+		present[cTree.fieldIdx] = true
+	}
+
+	for i := 0; i < st.NumField(); i++ {
+		if present[i] {
+			continue
+		}
+
+		field := st.Field(i)
+		fPath := joinPath(path, field.Name)
+		switch {
+		case !field.IsExported():
+			tr.skip(fPath, "unexported field")
+		case field.Tag.Get(tr.key) == "-":
+			tr.skip(fPath, fmt.Sprintf("tagged `%s:\"-\"`", tr.key))
+		default:
+			tr.skip(fPath, fmt.Sprintf("no %s operations", tr.key))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package recipe
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+type checkedInner struct {
+	A string `check:"nonempty"`
+	B string `check:"nonempty"`
+}
+
+type checkedWide struct {
+	First  string `check:"nonempty"`
+	Inner  checkedInner
+	Second string `check:"nonempty"`
+	Third  string `check:"nonempty"`
+}
+
+// newCheckExecutor returns an executor of `check` tags with combiner,
+// whose nonempty operation counts its calls into calls.
+func newCheckExecutor(t *testing.T, combiner Combiner, calls *atomic.Int64) *Executor {
+	t.Helper()
+
+	grammar, err := NewGrammarConfig().
+		SetKey("check").
+		SetWalkType(CombineWalk).
+		SetCombiner(combiner).
+		SetOpArity(OpUnary).
+		SetArity(GrammarArityVariadic).
+		SetFlatStructure().
+		SetFormat(FlatFormatDelimited, InlineSepComma).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := NewOpRegistry()
+	reg.RegisterOperation("nonempty", Unary(func(_ OpOpts, s string) (bool, error) {
+		calls.Add(1)
+		return s != "", nil
+	}))
+	return NewExecutor(reg, NewBuilder(grammar))
+}
+
+func TestExplainRecord(t *testing.T) {
+	var calls atomic.Int64
+	exec := newCheckExecutor(t, BoolAndCombiner{}, &calls)
+
+	w := checkedWide{First: "a", Inner: checkedInner{A: "", B: "b"}, Second: "x", Third: "y"}
+	trace, res, err := exec.Explain(ExplainRecord, CombineWalk, []any{&w}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != false || calls.Load() != 5 {
+		t.Errorf("combined %v in %d calls, want false in 5", res, calls.Load())
+	}
+
+	want := `First: op nonempty[a] -> true
+<root>: combine -> true
+Inner.A: op nonempty[] -> false
+Inner: combine -> false
+Inner.B: op nonempty[b] -> true
+Inner: combine -> false
+<root>: combine -> false
+Second: op nonempty[x] -> true
+<root>: combine -> false
+Third: op nonempty[y] -> true
+<root>: combine -> false
+<root>: combine -> false
+`
+	if got := trace.String(); got != want {
+		t.Errorf("traced\n%s\nwant\n%s", got, want)
+	}
+
+	if steps := trace.Field("Inner.A"); len(steps) != 1 || steps[0].Result != false {
+		t.Errorf("Inner.A steps %v", steps)
+	}
+}
+
+func TestExplainDryRun(t *testing.T) {
+	var calls atomic.Int64
+	exec := newCheckExecutor(t, BoolAndCombiner{}, &calls)
+
+	w := checkedWide{}
+	trace, _, err := exec.Explain(ExplainDryRun, CombineWalk, []any{&w}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("dry run called operations %d times", calls.Load())
+	}
+
+	ops := 0
+	for _, step := range trace.Steps {
+		switch step.Kind {
+		case TraceOp:
+			ops++
+		case TraceCombine:
+			t.Errorf("dry run combined: %v", step)
+		}
+	}
+	if ops != 5 {
+		t.Errorf("dry run recorded %d operations, want 5", ops)
+	}
+}
+
+func TestExplainTransformDryRun(t *testing.T) {
+	exec := newMaskExecutor(t, TransformWalk)
+
+	u := maskedUser{Name: "Ada"}
+	trace, _, err := exec.Explain(ExplainDryRun, TransformWalk, []any{&u}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "Ada" {
+		t.Errorf("dry run transformed Name to %q", u.Name)
+	}
+	if steps := trace.Field("Name"); len(steps) == 0 || steps[0].Kind != TraceOp || steps[0].Op != MaskOpRedact {
+		t.Errorf("Name steps %v", steps)
+	}
+}
+
+func TestExplainShortCircuit(t *testing.T) {
+	var calls atomic.Int64
+	exec := newCheckExecutor(t, ShortCircuitAndCombiner{}, &calls)
+
+	w := checkedWide{First: "a", Inner: checkedInner{A: "", B: "b"}, Second: "x", Third: "y"}
+	trace, res, err := exec.Explain(ExplainRecord, CombineWalk, []any{&w}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != false || calls.Load() != 2 {
+		t.Errorf("combined %v in %d calls, want false in 2", res, calls.Load())
+	}
+
+	for _, path := range []string{"Inner.B", "Second", "Third"} {
+		steps := trace.Field(path)
+		if len(steps) != 1 || steps[0].Kind != TraceSkip {
+			t.Errorf("%s steps %v, want a skip", path, steps)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package recipe
 import (
 	"fmt"
 	"reflect"
+	"unsafe"
 )
 
 var (
@@ -69,6 +70,45 @@ type Combiner interface {
 	Combine(acc, result any) any
 }
 
+// FieldCombiner is an optional interface for a [Combiner] that needs
+// the path of the field an operation result comes from.
+//
+// When implemented, the combine walk calls CombineField instead of
+// Combine for leaf operation results.
+type FieldCombiner interface {
+	Combiner
+
+	// CombineField combines accumulator with the result of an operation on
+	// the field at path, e.g. "Address.Zip".
+	CombineField(acc any, path string, result any) any
+}
+
+// ShortCircuitCombiner is an optional interface for a [Combiner] whose
+// result may be decided before every field is combined, e.g. a fail-fast
+// validator once a field is invalid.
+//
+// When implemented, the combine walk checks Done after each Combine or
+// CombineField, and skips the remaining operations, fields and nested
+// structs of a level once its accumulator is done. The accumulator is then
+// combined into the parent level as usual, which is checked in turn.
+//
+// Concurrent walks run every leaf, failing on any error, and only stop
+// combining their results.
+type ShortCircuitCombiner interface {
+	Combiner
+
+	// Done reports whether acc can no longer change, whatever is combined
+	// into it.
+	Done(acc any) bool
+}
+
+// CombineDone reports whether acc is done for combiner, if a
+// [ShortCircuitCombiner]. Called by generated code.
+func CombineDone(combiner Combiner, acc any) bool {
+	sc, ok := combiner.(ShortCircuitCombiner)
+	return ok && sc.Done(acc)
+}
+
 type BoolAndCombiner struct{}
 
 func (c BoolAndCombiner) Zero() any { return true }
@@ -86,6 +126,27 @@ func (c StringConcatCombiner) Combine(acc, result any) any {
 	return acc.(string) + result.(string)
 }
 
+// StringJoinCombiner joins non-empty string results with Sep, e.g. ", ".
+type StringJoinCombiner struct {
+	Sep string
+}
+
+func (c StringJoinCombiner) Zero() any { return "" }
+func (c StringJoinCombiner) Combine(acc, result any) any {
+	return joinNonEmpty(acc.(string), result.(string), c.Sep)
+}
+
+func joinNonEmpty(a, b, sep string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + sep + b
+	}
+}
+
 // Applier applies operation result to a field of the walked struct.
 //
 // Used for AppliedWalk recipes.
@@ -94,14 +155,56 @@ type Applier interface {
 	Apply(walked any, fieldOffset uintptr, fieldType reflect.Type, value any) error
 }
 
+// ZeroResult is the operation result that resets a field to its zero value.
+//
+// Nil results leave fields untouched, so operations return ZeroResult
+// when clearing the field is meant, e.g. [MergeOverride] with a nil last layer.
+var ZeroResult any = zeroResult{}
+
+type zeroResult struct{}
+
 type ReflectSetterApplier struct{}
 
 func (a ReflectSetterApplier) Apply(walked any, fieldOffset uintptr, fieldType reflect.Type, value any) error {
 	return setField(walked, fieldOffset, fieldType, value)
 }
 
+// setField writes value to the field at fieldOffset of the struct walked
+// points to.
+//
+// walked must be an unsafe.Pointer to the struct, as passed by the walkers.
 func setField(walked any, fieldOffset uintptr, fieldType reflect.Type, value any) error {
-	// TODO $$$SIMON
+	wPtr, ok := walked.(unsafe.Pointer)
+	if !ok {
+		return fmt.Errorf("%w: walked must be unsafe.Pointer, got %T", ErrOpMismatch, walked)
+	}
+
+	field := reflect.NewAt(fieldType, unsafe.Pointer(uintptr(wPtr)+fieldOffset)).Elem()
+	return assignValue(field, value)
+}
+
+// assignValue sets field to value, converting between compatible types.
+func assignValue(field reflect.Value, value any) error {
+	if value == nil || value == ZeroResult {
+		field.SetZero()
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Type().AssignableTo(field.Type()):
+		field.Set(rv)
+	case rv.Kind() == reflect.String && (field.Kind() != reflect.String || isTextUnmarshaler(field)):
+		return parseInto(field, rv.String())
+	case rv.Type() == stringSliceType && field.Kind() == reflect.Slice:
+		return parseSliceInto(field, value.([]string))
+	case rv.Type().ConvertibleTo(field.Type()) && !(field.Kind() == reflect.String && rv.Kind() >= reflect.Int && rv.Kind() <= reflect.Uintptr):
+		// Numeric to string conversions yield runes, never what an operation meant.
+		field.Set(rv.Convert(field.Type()))
+	default:
+		return fmt.Errorf("%w: cannot assign %s to %s", ErrOpMismatch, rv.Type(), field.Type())
+	}
+
 	return nil
 }
 